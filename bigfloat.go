@@ -0,0 +1,812 @@
+package oak
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+const (
+	// defaultPrec is the mantissa size (in bits) of
+	// high-precision floats unless otherwise set.
+	defaultPrec = 256
+
+	// maxPrec limits the mantissa size so that one
+	// calculation can't run (practically) forever.
+	maxPrec = 1 << 16
+
+	// guard is the number of extra bits used for
+	// intermediate results in the elementary functions.
+	guard = 64
+)
+
+// bigFloatOps are the binary operations on high-precision
+// floats, by name; they're given the working precision.
+var bigFloatOps = map[string]func(y, x *big.Float, prec uint) (*big.Float, bool){
+	"add": func(y, x *big.Float, prec uint) (*big.Float, bool) {
+		return newFloat(prec).Add(y, x), true
+	},
+	"sub": func(y, x *big.Float, prec uint) (*big.Float, bool) {
+		return newFloat(prec).Sub(y, x), true
+	},
+	"mul": func(y, x *big.Float, prec uint) (*big.Float, bool) {
+		return newFloat(prec).Mul(y, x), true
+	},
+	"div": func(y, x *big.Float, prec uint) (*big.Float, bool) {
+		if x.Sign() == 0 {
+			return nil, false
+		}
+
+		return newFloat(prec).Quo(y, x), true
+	},
+	"mod": func(y, x *big.Float, prec uint) (*big.Float, bool) {
+		if x.Sign() == 0 || x.IsInf() || y.IsInf() {
+			return nil, false
+		}
+
+		// the result has the sign of y, as for math.Mod
+
+		t, _ := newFloat(prec+guard).Quo(y, x).Int(nil)
+		q := newFloat(prec + guard).SetInt(t)
+
+		return newFloat(prec).Sub(y, q.Mul(q, x)), true
+	},
+	"pow": powFloat,
+	"dist": func(y, x *big.Float, prec uint) (*big.Float, bool) {
+		w := prec + guard
+		s := newFloat(w).Mul(y, y)
+
+		return newFloat(prec).Sqrt(s.Add(s, newFloat(w).Mul(x, x))), true
+	},
+	"max": func(y, x *big.Float, prec uint) (*big.Float, bool) {
+		if y.Cmp(x) > 0 {
+			return y, true
+		}
+
+		return x, true
+	},
+	"min": func(y, x *big.Float, prec uint) (*big.Float, bool) {
+		if y.Cmp(x) < 0 {
+			return y, true
+		}
+
+		return x, true
+	},
+}
+
+// bigFloatFuncs are the unary functions on high-precision
+// floats, by name; a false result means there's no real
+// answer (or none that can be represented), and the
+// float64 function will be used instead.
+var bigFloatFuncs = map[string]func(x *big.Float, prec uint) (*big.Float, bool){
+	"abs": func(x *big.Float, prec uint) (*big.Float, bool) {
+		return newFloat(prec).Abs(x), true
+	},
+	"sqr": func(x *big.Float, prec uint) (*big.Float, bool) {
+		return newFloat(prec).Mul(x, x), true
+	},
+	"cube": func(x *big.Float, prec uint) (*big.Float, bool) {
+		s := newFloat(prec+guard).Mul(x, x)
+		return newFloat(prec).Mul(s, x), true
+	},
+	"recp": func(x *big.Float, prec uint) (*big.Float, bool) {
+		if x.Sign() == 0 {
+			return nil, false
+		}
+
+		return newFloat(prec).Quo(newFloat(prec).SetInt64(1), x), true
+	},
+	"sqrt": func(x *big.Float, prec uint) (*big.Float, bool) {
+		if x.Sign() < 0 {
+			return nil, false
+		}
+
+		return newFloat(prec).Sqrt(x), true
+	},
+	"cbrt":  cbrtFloat,
+	"exp":   expFloat,
+	"ln":    lnFloat,
+	"log":   logFloat,
+	"alog":  alogFloat,
+	"sin":   sinFloat,
+	"cos":   cosFloat,
+	"tan":   tanFloat,
+	"asin":  asinFloat,
+	"acos":  acosFloat,
+	"atan":  atanFloat,
+	"trunc": truncFloat,
+	"floor": floorFloat,
+	"ceil":  ceilFloat,
+	"frac": func(x *big.Float, prec uint) (*big.Float, bool) {
+		t, ok := truncFloat(x, prec)
+
+		if !ok {
+			return nil, false
+		}
+
+		return newFloat(prec).Sub(x, t), true
+	},
+}
+
+var (
+	// SetPrecision pops the number of bits to be used
+	// for the mantissa of high-precision floats.
+	SetPrecision ExprFunc = func(m *Machine) error {
+		if len(m.stack) < 1 {
+			return errUnderflow
+		}
+
+		x := m.Pop()
+		f, ok := x.float()
+
+		if !ok || f < 2 || f > maxPrec {
+			return fmt.Errorf("prec: invalid operand x=%#v", x.V)
+		}
+
+		m.prec = uint(f)
+		return nil
+	}
+)
+
+// FloatLiteral returns an expression for a decimal literal,
+// which is a high-precision float in precise mode, or else
+// the number as it would otherwise be parsed.
+func FloatLiteral(s string, other Expr) ExprFunc {
+	return func(m *Machine) error {
+		if m.num != precise {
+			return other.Eval(m)
+		}
+
+		f, _, err := big.ParseFloat(s, 10, m.precision(), big.ToNearestEven)
+
+		if err != nil {
+			return fmt.Errorf("%s: %s", err, s)
+		}
+
+		m.Push(m.makeBigFloatVal(f))
+		return nil
+	}
+}
+
+// precision returns the mantissa size for high-precision floats.
+func (m *Machine) precision() uint {
+	if m.prec == 0 {
+		return defaultPrec
+	}
+
+	return m.prec
+}
+
+// binaryBigFloat applies an operation to high-precision
+// floats, falling back to float64 if there's no result.
+func (m *Machine) binaryBigFloat(op string, f func(float64, float64) float64, y, x *Value) (Value, error) {
+	p := m.precision()
+	a, ok1 := y.bigFloat(p)
+	b, ok2 := x.bigFloat(p)
+
+	if g, ok := bigFloatOps[op]; ok && ok1 && ok2 {
+		if r, ok := safely(func() (*big.Float, bool) { return g(a, b, p) }); ok {
+			// a huge power takes forever to show, just
+			// as it would to calculate exactly
+
+			if e := r.MantExp(nil); !r.IsInf() && (e > maxBits || e < -maxBits) {
+				return Value{}, fmt.Errorf("%s: %w", op, errTooLarge)
+			}
+
+			return m.makeBigFloatVal(r), nil
+		}
+	}
+
+	c, ok1 := y.float()
+	d, ok2 := x.float()
+
+	if !ok1 || !ok2 {
+		return Value{}, fmt.Errorf("%s: mismatched operands y=%#v, x=%#v", op, y.V, x.V)
+	}
+
+	return m.makeFloatVal(f(c, d)), nil
+}
+
+// unaryBigFloat applies a function to a high-precision
+// float (converting angles for trigonometry as needed),
+// falling back to float64 if there's no result.
+func (m *Machine) unaryBigFloat(op string, f func(float64) float64, x *Value) Value {
+	p := m.precision()
+	a := x.V.(*big.Float)
+
+	if g, ok := bigFloatFuncs[op]; ok {
+		r, ok := safely(func() (*big.Float, bool) {
+			t := a
+
+			if x.M == degrees && isTrig(op) {
+				t = toRadians(a, p)
+			}
+
+			r, ok := g(t, p)
+
+			if ok && x.M == degrees && isInverseTrig(op) {
+				r = toDegrees(r, p)
+			}
+
+			return r, ok
+		})
+
+		if ok {
+			return m.makeBigFloatVal(r)
+		}
+	}
+
+	b, _ := x.float()
+	r := f(b)
+
+	if z, ok := m.complexResult(op, x, b, r); ok {
+		return z
+	}
+
+	return m.makeFloatVal(r)
+}
+
+func isTrig(op string) bool {
+	return op == "sin" || op == "cos" || op == "tan"
+}
+
+func isInverseTrig(op string) bool {
+	return op == "asin" || op == "acos" || op == "atan"
+}
+
+// safely runs a calculation which may fail because its
+// result would be NaN (which big.Float can't represent).
+func safely(f func() (*big.Float, bool)) (r *big.Float, ok bool) {
+	defer func() {
+		if e := recover(); e != nil {
+			if _, nan := e.(big.ErrNaN); !nan {
+				panic(e)
+			}
+
+			r, ok = nil, false
+		}
+	}()
+
+	return f()
+}
+
+// bigFloat returns the value as a high-precision float,
+// or false if it isn't a (real, non-NaN) number.
+func (v Value) bigFloat(prec uint) (*big.Float, bool) {
+	switch v.T {
+	case bigfloat:
+		return v.V.(*big.Float), true
+
+	case floater:
+		f := v.V.(float64)
+
+		if math.IsNaN(f) {
+			return nil, false
+		}
+
+		return newFloat(prec).SetFloat64(f), true
+
+	case integer:
+		return newFloat(prec).SetUint64(uint64(v.V.(uint))), true
+
+	case bigint:
+		return newFloat(prec).SetInt(v.V.(*big.Int)), true
+
+	case rational:
+		return newFloat(prec).SetRat(v.V.(*big.Rat)), true
+	}
+
+	return nil, false
+}
+
+func newFloat(prec uint) *big.Float {
+	return new(big.Float).SetPrec(prec)
+}
+
+// piFloat calculates π by the Gauss-Legendre algorithm,
+// which doubles the number of correct digits each time.
+func piFloat(prec uint) *big.Float {
+	w := prec + guard
+
+	one := newFloat(w).SetInt64(1)
+	two := newFloat(w).SetInt64(2)
+
+	a := newFloat(w).Set(one)
+	b := newFloat(w).Sqrt(newFloat(w).Quo(one, two))
+	t := newFloat(w).SetFloat64(0.25)
+	p := newFloat(w).Set(one)
+
+	for i := 0; i < 64; i++ {
+		n := newFloat(w).Add(a, b)
+		n.Quo(n, two)
+
+		d := newFloat(w).Sub(a, n)
+		d.Mul(d, d)
+		d.Mul(d, p)
+
+		b.Sqrt(newFloat(w).Mul(a, b))
+		t.Sub(t, d)
+		p.Mul(p, two)
+
+		done := newFloat(w).Sub(a, n).Sign() == 0 || newFloat(w).Sub(a, b).MantExp(nil) < -int(w)
+		a = n
+
+		if done {
+			break
+		}
+	}
+
+	r := newFloat(w).Add(a, b)
+	r.Mul(r, r)
+
+	return newFloat(prec).Quo(r, t.Mul(t, newFloat(w).SetInt64(4)))
+}
+
+func toRadians(x *big.Float, prec uint) *big.Float {
+	w := prec + guard
+	r := newFloat(w).Mul(x, piFloat(w))
+
+	return newFloat(prec).Quo(r, newFloat(w).SetInt64(180))
+}
+
+func toDegrees(x *big.Float, prec uint) *big.Float {
+	w := prec + guard
+	r := newFloat(w).Mul(x, newFloat(w).SetInt64(180))
+
+	return newFloat(prec).Quo(r, piFloat(w))
+}
+
+// small reports whether the term is negligible
+// at the given working precision.
+func small(t *big.Float, w uint) bool {
+	return t.Sign() == 0 || t.MantExp(nil) < -int(w)
+}
+
+// expFloat calculates e**x by the Taylor series on x / 2**k,
+// and then squares the result k times.
+func expFloat(x *big.Float, prec uint) (*big.Float, bool) {
+	if x.IsInf() {
+		if x.Sign() > 0 {
+			return newFloat(prec).SetInf(false), true
+		}
+
+		return newFloat(prec), true
+	}
+
+	// beyond this, the result is out of range
+
+	if f, _ := x.Float64(); f > 1.4e9 {
+		return newFloat(prec).SetInf(false), true
+	} else if f < -1.4e9 {
+		return newFloat(prec), true
+	}
+
+	k := 0
+
+	if e := x.MantExp(nil); e > -8 {
+		k = e + 8
+	}
+
+	w := prec + guard + uint(k)
+	r := newFloat(w).SetMantExp(x, -k)
+
+	s := newFloat(w).SetInt64(1)
+	t := newFloat(w).SetInt64(1)
+
+	for n := int64(1); ; n++ {
+		t.Mul(t, r)
+		t.Quo(t, newFloat(w).SetInt64(n))
+		s.Add(s, t)
+
+		if small(t, w) {
+			break
+		}
+	}
+
+	for ; k > 0; k-- {
+		s.Mul(s, s)
+	}
+
+	return newFloat(prec).Set(s), true
+}
+
+// lnFloat calculates the natural logarithm using Newton's method
+// (well, Halley's) on the mantissa, adding the binary exponent
+// times ln(2).
+func lnFloat(x *big.Float, prec uint) (*big.Float, bool) {
+	switch {
+	case x.Sign() < 0:
+		return nil, false
+	case x.Sign() == 0:
+		return newFloat(prec).SetInf(true), true
+	case x.IsInf():
+		return newFloat(prec).SetInf(false), true
+	}
+
+	w := prec + guard
+	mant := newFloat(w)
+	e := x.MantExp(mant)
+
+	r := lnNewton(mant, w)
+
+	if e != 0 {
+		l2 := lnNewton(newFloat(w).SetInt64(2), w)
+		r.Add(r, l2.Mul(l2, newFloat(w).SetInt64(int64(e))))
+	}
+
+	return newFloat(prec).Set(r), true
+}
+
+// lnNewton finds y where e**y = x, for a moderate x,
+// starting from the float64 result.
+func lnNewton(x *big.Float, w uint) *big.Float {
+	f, _ := x.Float64()
+	y := newFloat(w).SetFloat64(math.Log(f))
+
+	for i := 0; i < 64; i++ {
+		e, _ := expFloat(y, w)
+
+		n := newFloat(w).Sub(x, e)
+		d := newFloat(w).Add(x, e)
+		n.Quo(n, d)
+		n.Mul(n, newFloat(w).SetInt64(2))
+
+		y.Add(y, n)
+
+		if small(n, w-8) {
+			break
+		}
+	}
+
+	return y
+}
+
+func logFloat(x *big.Float, prec uint) (*big.Float, bool) {
+	w := prec + guard
+	r, ok := lnFloat(x, w)
+
+	if !ok {
+		return nil, false
+	}
+
+	l, _ := lnFloat(newFloat(w).SetInt64(10), w)
+	return newFloat(prec).Quo(r, l), true
+}
+
+func alogFloat(x *big.Float, prec uint) (*big.Float, bool) {
+	w := prec + guard
+	l, _ := lnFloat(newFloat(w).SetInt64(10), w)
+
+	return expFloat(l.Mul(l, x), prec)
+}
+
+// powFloat calculates y**x, exactly by repeated squaring
+// if x is a (modest) integer, or else as e**(x ln y).
+func powFloat(y, x *big.Float, prec uint) (*big.Float, bool) {
+	w := prec + guard
+
+	if x.IsInt() && !x.IsInf() {
+		if n, acc := x.Int64(); acc == big.Exact && n > -(1<<20) && n < 1<<20 {
+			r := newFloat(w).SetInt64(1)
+			b := newFloat(w).Set(y)
+			neg := n < 0
+
+			if neg {
+				n = -n
+			}
+
+			for ; n > 0; n >>= 1 {
+				if n&1 == 1 {
+					r.Mul(r, b)
+				}
+
+				b.Mul(b, b)
+			}
+
+			if neg {
+				if r.Sign() == 0 {
+					return nil, false
+				}
+
+				r.Quo(newFloat(w).SetInt64(1), r)
+			}
+
+			return newFloat(prec).Set(r), true
+		}
+	}
+
+	switch {
+	case y.Sign() < 0:
+		return nil, false
+	case y.Sign() == 0:
+		if x.Sign() > 0 {
+			return newFloat(prec), true
+		}
+
+		return nil, false
+	}
+
+	l, _ := lnFloat(y, w)
+	return expFloat(l.Mul(l, x), prec)
+}
+
+// cbrtFloat finds the cube root by Newton's method.
+func cbrtFloat(x *big.Float, prec uint) (*big.Float, bool) {
+	if x.Sign() == 0 || x.IsInf() {
+		return newFloat(prec).Set(x), true
+	}
+
+	w := prec + guard
+	a := newFloat(w).Abs(x)
+
+	// start from the float64 result, scaled
+	// so that it will be in range
+
+	mant := newFloat(w)
+	e := a.MantExp(mant)
+
+	f, _ := mant.Float64()
+	y := newFloat(w).SetFloat64(math.Cbrt(f * math.Pow(2, float64(e%3))))
+	y.SetMantExp(y, e/3)
+
+	three := newFloat(w).SetInt64(3)
+
+	for i := 0; i < 64; i++ {
+		// y = y - (y**3 - a) / 3y**2
+
+		y2 := newFloat(w).Mul(y, y)
+		d := newFloat(w).Mul(y2, y)
+		d.Sub(d, a)
+		d.Quo(d, y2.Mul(y2, three))
+
+		y.Sub(y, d)
+
+		if small(newFloat(w).Quo(d, y), w-8) {
+			break
+		}
+	}
+
+	if x.Sign() < 0 {
+		y.Neg(y)
+	}
+
+	return newFloat(prec).Set(y), true
+}
+
+// reduce returns x modulo 2π, in the range [-π, π].
+func reduce(x *big.Float, w uint) *big.Float {
+	if e := x.MantExp(nil); e > 0 {
+		w += uint(e)
+	}
+
+	tau := piFloat(w)
+	tau.Mul(tau, newFloat(w).SetInt64(2))
+
+	q := newFloat(w).Quo(x, tau)
+	q.Add(q, newFloat(w).SetFloat64(0.5*float64(q.Sign())))
+
+	n, _ := q.Int(nil)
+	r := newFloat(w).SetInt(n)
+
+	return r.Sub(x, r.Mul(r, tau))
+}
+
+// sinCos calculates both the sine and cosine of x
+// by their Taylor series after reducing x to [-π, π].
+func sinCos(x *big.Float, prec uint) (*big.Float, *big.Float) {
+	w := prec + guard
+	r := reduce(x, w)
+	r2 := newFloat(w).Mul(r, r)
+
+	s := newFloat(w).Set(r)
+	c := newFloat(w).SetInt64(1)
+	ts := newFloat(w).Set(r)
+	tc := newFloat(w).SetInt64(1)
+
+	for n := int64(1); ; n++ {
+		ts.Mul(ts, r2)
+		ts.Quo(ts, newFloat(w).SetInt64(-(2*n)*(2*n+1)))
+		s.Add(s, ts)
+
+		tc.Mul(tc, r2)
+		tc.Quo(tc, newFloat(w).SetInt64(-(2*n-1)*(2*n)))
+		c.Add(c, tc)
+
+		if small(ts, w) && small(tc, w) {
+			break
+		}
+	}
+
+	return newFloat(prec).Set(s), newFloat(prec).Set(c)
+}
+
+func sinFloat(x *big.Float, prec uint) (*big.Float, bool) {
+	if x.IsInf() {
+		return nil, false
+	}
+
+	s, _ := sinCos(x, prec)
+	return s, true
+}
+
+func cosFloat(x *big.Float, prec uint) (*big.Float, bool) {
+	if x.IsInf() {
+		return nil, false
+	}
+
+	_, c := sinCos(x, prec)
+	return c, true
+}
+
+func tanFloat(x *big.Float, prec uint) (*big.Float, bool) {
+	if x.IsInf() {
+		return nil, false
+	}
+
+	s, c := sinCos(x, prec+guard)
+
+	if c.Sign() == 0 {
+		return nil, false
+	}
+
+	return newFloat(prec).Quo(s, c), true
+}
+
+// atanFloat calculates the arctangent by the Taylor series
+// after halving the angle until it's small.
+func atanFloat(x *big.Float, prec uint) (*big.Float, bool) {
+	w := prec + guard
+
+	if x.IsInf() {
+		h := piFloat(w)
+		h.Quo(h, newFloat(w).SetInt64(int64(2*x.Sign())))
+
+		return newFloat(prec).Set(h), true
+	}
+
+	one := newFloat(w).SetInt64(1)
+	r := newFloat(w).Set(x)
+	k := 0
+
+	// atan(x) = 2 atan(x / (1 + sqrt(1 + x**2)))
+
+	for ; !small(r, 8) && k < 64; k++ {
+		d := newFloat(w).Mul(r, r)
+		d.Add(d, one)
+		d.Sqrt(d)
+		d.Add(d, one)
+		r.Quo(r, d)
+	}
+
+	r2 := newFloat(w).Mul(r, r)
+	s := newFloat(w).Set(r)
+	t := newFloat(w).Set(r)
+
+	for n := int64(1); ; n++ {
+		t.Mul(t, r2)
+		t.Neg(t)
+
+		u := newFloat(w).Quo(t, newFloat(w).SetInt64(2*n+1))
+		s.Add(s, u)
+
+		if small(u, w) {
+			break
+		}
+	}
+
+	return newFloat(prec).SetMantExp(s, k), true
+}
+
+func asinFloat(x *big.Float, prec uint) (*big.Float, bool) {
+	w := prec + guard
+	one := newFloat(w).SetInt64(1)
+
+	switch c := newFloat(w).Abs(x).Cmp(one); {
+	case c > 0:
+		return nil, false
+
+	case c == 0:
+		h := piFloat(w)
+		h.Quo(h, newFloat(w).SetInt64(int64(2*x.Sign())))
+
+		return newFloat(prec).Set(h), true
+	}
+
+	// asin(x) = atan(x / sqrt(1 - x**2))
+
+	d := newFloat(w).Mul(x, x)
+	d.Sub(one, d)
+	d.Sqrt(d)
+
+	return atanFloat(d.Quo(x, d), prec)
+}
+
+func acosFloat(x *big.Float, prec uint) (*big.Float, bool) {
+	w := prec + guard
+	a, ok := asinFloat(x, w)
+
+	if !ok {
+		return nil, false
+	}
+
+	h := piFloat(w)
+	h.Quo(h, newFloat(w).SetInt64(2))
+
+	return newFloat(prec).Sub(h, a), true
+}
+
+func truncFloat(x *big.Float, prec uint) (*big.Float, bool) {
+	if x.IsInf() {
+		return newFloat(prec).Set(x), true
+	}
+
+	t, _ := x.Int(nil)
+	return newFloat(prec).SetInt(t), true
+}
+
+func floorFloat(x *big.Float, prec uint) (*big.Float, bool) {
+	t, ok := truncFloat(x, prec)
+
+	if ok && x.Sign() < 0 && t.Cmp(x) != 0 {
+		t.Sub(t, newFloat(prec).SetInt64(1))
+	}
+
+	return t, ok
+}
+
+func ceilFloat(x *big.Float, prec uint) (*big.Float, bool) {
+	t, ok := truncFloat(x, prec)
+
+	if ok && x.Sign() > 0 && t.Cmp(x) != 0 {
+		t.Add(t, newFloat(prec).SetInt64(1))
+	}
+
+	return t, ok
+}
+
+// formatBigFloat shows a high-precision float in the
+// current display mode, with all its digits if free.
+func (m *Machine) formatBigFloat(f *big.Float) string {
+	switch m.disp {
+	case fixed:
+		return f.Text('f', int(m.digits))
+
+	case scientific:
+		return f.Text('e', int(m.digits))
+
+	case engineering:
+		if f.Sign() == 0 || f.IsInf() {
+			return f.Text('e', int(m.digits))
+		}
+
+		// we find the decimal exponent from the scientific
+		// form, and then scale the number to a multiple of 3
+
+		s := f.Text('e', int(m.digits))
+		e, _ := strconv.Atoi(s[strings.LastIndexByte(s, 'e')+1:])
+
+		k := e - ((e%3)+3)%3
+		d := int(m.digits) - (e - k)
+
+		if d < 0 {
+			d = 0
+		}
+
+		p := f.Prec() + guard
+		t := newFloat(p).SetInt64(10)
+		t, _ = powFloat(t, newFloat(p).SetInt64(int64(-k)), p)
+		t.Mul(t, f)
+
+		sign := '+'
+
+		if k < 0 {
+			sign, k = '-', -k
+		}
+
+		return fmt.Sprintf("%se%c%02d", t.Text('f', d), sign, k)
+	}
+
+	return f.Text('g', -1)
+}
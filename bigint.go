@@ -0,0 +1,347 @@
+package oak
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"math/bits"
+	"strings"
+)
+
+// bigOps are the binary operations which give exact
+// results on big integers, by name; a nil result means
+// there's no exact (integer) answer.
+var bigOps = map[string]func(y, x *big.Int) *big.Int{
+	"add": func(y, x *big.Int) *big.Int { return new(big.Int).Add(y, x) },
+	"sub": func(y, x *big.Int) *big.Int { return new(big.Int).Sub(y, x) },
+	"mul": func(y, x *big.Int) *big.Int { return new(big.Int).Mul(y, x) },
+	"div": func(y, x *big.Int) *big.Int {
+		if x.Sign() == 0 {
+			return nil
+		}
+
+		q, r := new(big.Int).QuoRem(y, x, new(big.Int))
+
+		if r.Sign() != 0 {
+			return nil
+		}
+
+		return q
+	},
+	"mod": func(y, x *big.Int) *big.Int {
+		if x.Sign() == 0 {
+			return nil
+		}
+
+		return new(big.Int).Rem(y, x)
+	},
+	"pow": func(y, x *big.Int) *big.Int {
+		if x.Sign() < 0 {
+			return nil
+		}
+
+		return new(big.Int).Exp(y, x, nil)
+	},
+	"max": func(y, x *big.Int) *big.Int {
+		if y.Cmp(x) > 0 {
+			return y
+		}
+
+		return x
+	},
+	"min": func(y, x *big.Int) *big.Int {
+		if y.Cmp(x) < 0 {
+			return y
+		}
+
+		return x
+	},
+	"comb": func(y, x *big.Int) *big.Int {
+		if y.Sign() < 0 || x.Sign() < 0 || x.Cmp(y) > 0 || !y.IsInt64() {
+			return nil
+		}
+
+		return new(big.Int).Binomial(y.Int64(), x.Int64())
+	},
+	"perm": func(y, x *big.Int) *big.Int {
+		if y.Sign() < 0 || x.Sign() < 0 || x.Cmp(y) > 0 || !y.IsInt64() {
+			return nil
+		}
+
+		return new(big.Int).MulRange(y.Int64()-x.Int64()+1, y.Int64())
+	},
+}
+
+// bigFuncs are the unary functions which give exact
+// results on big integers, by name.
+var bigFuncs = map[string]func(x *big.Int) *big.Int{
+	"abs":  func(x *big.Int) *big.Int { return new(big.Int).Abs(x) },
+	"sqr":  func(x *big.Int) *big.Int { return new(big.Int).Mul(x, x) },
+	"cube": func(x *big.Int) *big.Int { return new(big.Int).Mul(x, new(big.Int).Mul(x, x)) },
+	"fact": func(x *big.Int) *big.Int {
+		if x.Sign() < 0 || !x.IsInt64() {
+			return nil
+		}
+
+		return new(big.Int).MulRange(1, x.Int64())
+	},
+}
+
+// bigBitwiseUnary are the unary bitwise functions
+// on big integers, by name.
+var bigBitwiseUnary = map[string]func(x *big.Int) *big.Int{
+	"not": func(x *big.Int) *big.Int { return new(big.Int).Not(x) },
+	"popcnt": func(x *big.Int) *big.Int {
+		var n int
+
+		for _, w := range x.Bits() {
+			n += bits.OnesCount(uint(w))
+		}
+
+		return big.NewInt(int64(n))
+	},
+	"ctz": func(x *big.Int) *big.Int { return big.NewInt(int64(x.TrailingZeroBits())) },
+}
+
+// bigBitwise are the bitwise operations on big integers.
+var bigBitwise = map[string]func(y, x *big.Int) *big.Int{
+	"and": func(y, x *big.Int) *big.Int { return new(big.Int).And(y, x) },
+	"or":  func(y, x *big.Int) *big.Int { return new(big.Int).Or(y, x) },
+	"xor": func(y, x *big.Int) *big.Int { return new(big.Int).Xor(y, x) },
+	"shl": func(y, x *big.Int) *big.Int {
+		if x.Sign() < 0 || !x.IsUint64() {
+			return nil
+		}
+
+		return new(big.Int).Lsh(y, uint(x.Uint64()))
+	},
+	"shr": func(y, x *big.Int) *big.Int {
+		if x.Sign() < 0 || !x.IsUint64() {
+			return nil
+		}
+
+		return new(big.Int).Rsh(y, uint(x.Uint64()))
+	},
+	"sb": func(y, x *big.Int) *big.Int {
+		if x.Sign() < 0 || !x.IsInt64() {
+			return nil
+		}
+
+		return new(big.Int).SetBit(y, int(x.Int64()), 1)
+	},
+	"cb": func(y, x *big.Int) *big.Int {
+		if x.Sign() < 0 || !x.IsInt64() {
+			return nil
+		}
+
+		return new(big.Int).SetBit(y, int(x.Int64()), 0)
+	},
+	"b?": func(y, x *big.Int) *big.Int {
+		if x.Sign() < 0 || !x.IsInt64() {
+			return nil
+		}
+
+		return big.NewInt(int64(y.Bit(int(x.Int64()))))
+	},
+}
+
+// BigLiteral returns an expression for an integer literal,
+// which is a big integer in big-integer mode, or else the
+// number as it would otherwise be parsed (or its error).
+func BigLiteral(n *big.Int, other Expr, err error) ExprFunc {
+	return func(m *Machine) error {
+		if m.num == bigints {
+			m.Push(m.makeBigVal(n))
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		return other.Eval(m)
+	}
+}
+
+// parseBig reads an integer literal as a big integer; in
+// a base other than 10, it may have a prefix (0b, 0x, 0r,
+// or 0 for octal) as for other integers, and 0r may be
+// used in any base.
+func parseBig(s string, base int) (*big.Int, bool) {
+	if base != 10 || isRadix(s) {
+		d, b, err := splitRadix(strings.TrimPrefix(s, "-"), base)
+
+		if err != nil {
+			return nil, false
+		}
+
+		if strings.HasPrefix(s, "-") {
+			d = "-" + d
+		}
+
+		return new(big.Int).SetString(d, b)
+	}
+
+	return new(big.Int).SetString(s, base)
+}
+
+// maxBits limits the size of an exact power (about
+// 300,000 decimal digits), which could otherwise take
+// forever (or run out of memory) to calculate.
+const maxBits = 1 << 20
+
+// tooLarge reports whether a power y**x would be
+// more than maxBits long.
+func tooLarge(y, x *big.Int) bool {
+	if y.CmpAbs(big.NewInt(1)) <= 0 || x.Sign() <= 0 {
+		return false
+	}
+
+	n := new(big.Int).Mul(big.NewInt(int64(y.BitLen()-1)), x)
+	return n.Cmp(big.NewInt(maxBits)) > 0
+}
+
+// binaryBig applies an operation to big integers, giving an
+// exact result where there is one, or else a float.
+func (m *Machine) binaryBig(op string, f func(float64, float64) float64, y, x *Value) (Value, error) {
+	a, ok1 := y.bigInt()
+	b, ok2 := x.bigInt()
+
+	if ok1 && ok2 {
+		if op == "pow" && tooLarge(a, b) {
+			return Value{}, fmt.Errorf("%s: %w", op, errTooLarge)
+		}
+
+		if g, ok := bigOps[op]; ok {
+			if r := g(a, b); r != nil {
+				return m.makeBigVal(r), nil
+			}
+		}
+	}
+
+	// there's no exact result, so we'll use floats
+
+	c, ok1 := y.float()
+	d, ok2 := x.float()
+
+	if !ok1 || !ok2 {
+		return Value{}, fmt.Errorf("%s: mismatched operands y=%#v, x=%#v", op, y.V, x.V)
+	}
+
+	return m.makeFloatVal(f(c, d)), nil
+}
+
+// unaryBig applies a function to a big integer, giving
+// an exact result where there is one, or else a float.
+func (m *Machine) unaryBig(op string, f func(float64) float64, x *Value) Value {
+	if g, ok := bigFuncs[op]; ok {
+		if r := g(x.V.(*big.Int)); r != nil {
+			return m.makeBigVal(r)
+		}
+	}
+
+	a, _ := x.float()
+	return m.makeFloatVal(f(a))
+}
+
+// bitwiseBig applies a bitwise operation to big integers
+// (either of which may also be an ordinary integer).
+func (m *Machine) bitwiseBig(op string, y, x *Value) (Value, error) {
+	a, ok1 := y.bigInt()
+	b, ok2 := x.bigInt()
+
+	g, ok := bigBitwise[op]
+
+	if !ok {
+		return Value{}, fmt.Errorf("%s: not supported for big integers", op)
+	}
+
+	if ok1 && ok2 {
+		if r := g(a, b); r != nil {
+			return m.makeBigVal(r), nil
+		}
+	}
+
+	return Value{}, fmt.Errorf("%s: mismatched operands y=%#v, x=%#v", op, y.V, x.V)
+}
+
+// unaryBitwiseBig applies a bitwise function to a big integer.
+func (m *Machine) unaryBitwiseBig(op string, x *Value) (Value, error) {
+	if g, ok := bigBitwiseUnary[op]; ok {
+		return m.makeBigVal(g(x.V.(*big.Int))), nil
+	}
+
+	return Value{}, fmt.Errorf("%s: not supported for big integers", op)
+}
+
+// bigInt returns the value as a big integer, or false
+// if it isn't an integer (including a float with a
+// fractional part).
+func (v Value) bigInt() (*big.Int, bool) {
+	switch v.T {
+	case bigint:
+		return v.V.(*big.Int), true
+
+	case integer:
+		return new(big.Int).SetUint64(uint64(v.V.(uint))), true
+
+	case floater:
+		f := v.V.(float64)
+
+		if f != math.Trunc(f) || math.IsInf(f, 0) {
+			return nil, false
+		}
+
+		n, _ := big.NewFloat(f).Int(nil)
+		return n, true
+	}
+
+	return nil, false
+}
+
+// truncBig returns the integer part of a float as a big
+// integer (for conversion to a binary base).
+func truncBig(f float64) *big.Int {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return new(big.Int)
+	}
+
+	n, _ := big.NewFloat(f).Int(nil)
+	return n
+}
+
+// formatBig shows a big integer in the current base,
+// with the same prefixes and minimum number of digits
+// as other integers.
+func (m *Machine) formatBig(n *big.Int) string {
+	var s string
+
+	pad := func(base, min int) string {
+		t := new(big.Int).Abs(n).Text(base)
+
+		if len(t) < min {
+			t = strings.Repeat("0", min-len(t)) + t
+		}
+
+		return t
+	}
+
+	switch m.base {
+	case base02:
+		s = "0b" + pad(2, 8)
+	case base08:
+		s = "0" + pad(8, 3)
+	case base16:
+		s = "0x" + pad(16, 4)
+	case baseN:
+		s = fmt.Sprintf("0r%d:%s", m.nbase, pad(m.nbase, 1))
+	default:
+		return n.String()
+	}
+
+	if n.Sign() < 0 {
+		return "-" + s
+	}
+
+	return s
+}
@@ -0,0 +1,115 @@
+package oak
+
+import "math/bits"
+
+// RotateLeft rotates y left by x bits within the word;
+// the carry is the last bit rotated around.
+func RotateLeft(s *intState, y, x uint) uint {
+	n := s.bits()
+	k := x % n
+
+	if x == 0 {
+		return y
+	}
+
+	r := (y<<k | y>>(n-k)) & s.mask()
+	s.carry = r&1 == 1
+
+	return r
+}
+
+// RotateRight rotates y right by x bits within the word;
+// the carry is the last bit rotated around.
+func RotateRight(s *intState, y, x uint) uint {
+	n := s.bits()
+	k := x % n
+
+	if x == 0 {
+		return y
+	}
+
+	r := (y>>k | y<<(n-k)) & s.mask()
+	s.carry = r>>(n-1)&1 == 1
+
+	return r
+}
+
+// RotateLeftCarry rotates y left by x bits through the
+// carry, as if it were one more bit above the word.
+func RotateLeftCarry(s *intState, y, x uint) uint {
+	n := s.bits()
+
+	for i := uint(0); i < x%(n+1); i++ {
+		c := y>>(n-1)&1 == 1
+		y = y << 1 & s.mask()
+
+		if s.carry {
+			y |= 1
+		}
+
+		s.carry = c
+	}
+
+	return y
+}
+
+// RotateRightCarry rotates y right by x bits through the
+// carry, as if it were one more bit above the word.
+func RotateRightCarry(s *intState, y, x uint) uint {
+	n := s.bits()
+
+	for i := uint(0); i < x%(n+1); i++ {
+		c := y&1 == 1
+		y >>= 1
+
+		if s.carry {
+			y |= 1 << (n - 1)
+		}
+
+		s.carry = c
+	}
+
+	return y
+}
+
+// SetBit sets bit x (from 0) of y; x must be in the word.
+func SetBit(_ *intState, y, x uint) uint {
+	return y | 1<<x
+}
+
+// ClearBit clears bit x (from 0) of y.
+func ClearBit(_ *intState, y, x uint) uint {
+	return y &^ (1 << x)
+}
+
+// TestBit returns bit x (from 0) of y as a flag.
+func TestBit(_ *intState, y, x uint) uint {
+	return y >> x & 1
+}
+
+// ByteSwap reverses the order of the bytes in the word
+// (truncated if the word size isn't a multiple of 8).
+func ByteSwap(s *intState, x uint) uint {
+	n := (s.bits() + 7) / 8 * 8
+	return bits.ReverseBytes(x) >> (64 - n)
+}
+
+// BitReverse reverses the order of the bits in the word.
+func BitReverse(s *intState, x uint) uint {
+	return bits.Reverse(x) >> (64 - s.bits())
+}
+
+// LeadingZeros counts the zero bits at the top of the word.
+func LeadingZeros(s *intState, x uint) uint {
+	return uint(bits.LeadingZeros(x)) - (64 - s.bits())
+}
+
+// TrailingZeros counts the zero bits at the bottom of the
+// word (all of them if x is zero).
+func TrailingZeros(s *intState, x uint) uint {
+	if x == 0 {
+		return s.bits()
+	}
+
+	return uint(bits.TrailingZeros(x))
+}
@@ -9,7 +9,9 @@ import (
 var (
 	errUnderflow  = errors.New("stack underflow")
 	errNoStats    = errors.New("stats empty")
+	errNoPoint    = errors.New("no such data point")
 	errNoSolution = errors.New("no solution")
+	errTooLarge   = errors.New("result too large")
 )
 
 // Last returns the last top-of-stack value that
@@ -168,29 +170,47 @@ func (m *Machine) Delete() error {
 	return fmt.Errorf("delete: invalid operand")
 }
 
-func (m *Machine) SumXY(x, y *Value) {
+func (m *Machine) SumXY(x, y *Value) error {
+	xf, ok1 := x.V.(float64)
+	yf, ok2 := y.V.(float64)
+
+	if !ok1 || !ok2 {
+		return fmt.Errorf("invalid operands y=%#v, x=%#v", y.V, x.V)
+	}
+
 	if m.stats == nil || m.stats[sumn] == nil {
 		m.initStats()
 	}
 
-	xf := x.V.(float64)
-	yf := y.V.(float64)
-
 	m.stats[sumn].V = m.stats[sumn].V.(float64) + 1
 	m.stats[xsum].V = m.stats[xsum].V.(float64) + xf
 	m.stats[xsqsum].V = m.stats[xsqsum].V.(float64) + (xf * xf)
 	m.stats[ysum].V = m.stats[ysum].V.(float64) + yf
 	m.stats[ysqsum].V = m.stats[ysqsum].V.(float64) + (yf * yf)
 	m.stats[xyprod].V = m.stats[xyprod].V.(float64) + (xf * yf)
+
+	m.addData(xf, yf)
+	return nil
 }
 
-func (m *Machine) RemoveXY(x, y *Value) {
+func (m *Machine) RemoveXY(x, y *Value) error {
 	if m.stats == nil || m.stats[sumn] == nil {
-		return
+		return errNoStats
 	}
 
-	xf := x.V.(float64)
-	yf := y.V.(float64)
+	xf, ok1 := x.V.(float64)
+	yf, ok2 := y.V.(float64)
+
+	if !ok1 || !ok2 {
+		return fmt.Errorf("invalid operands y=%#v, x=%#v", y.V, x.V)
+	}
+
+	// the point must have been entered, so the
+	// sums don't change if it wasn't
+
+	if err := m.removeData(xf, yf); err != nil {
+		return err
+	}
 
 	m.stats[sumn].V = m.stats[sumn].V.(float64) - 1
 	m.stats[xsum].V = m.stats[xsum].V.(float64) - xf
@@ -198,30 +218,49 @@ func (m *Machine) RemoveXY(x, y *Value) {
 	m.stats[ysum].V = m.stats[ysum].V.(float64) - yf
 	m.stats[ysqsum].V = m.stats[ysqsum].V.(float64) - (yf * yf)
 	m.stats[xyprod].V = m.stats[xyprod].V.(float64) - (xf * yf)
+
+	return nil
 }
 
-func (m *Machine) SumX(x *Value) {
+func (m *Machine) SumX(x *Value) error {
+	xf, ok := x.V.(float64)
+
+	if !ok {
+		return fmt.Errorf("invalid operand x=%#v", x.V)
+	}
+
 	if m.stats == nil || m.stats[sumn] == nil {
 		m.initStats()
 	}
 
-	xf := x.V.(float64)
-
 	m.stats[sumn].V = m.stats[sumn].V.(float64) + 1
 	m.stats[xsum].V = m.stats[xsum].V.(float64) + xf
 	m.stats[xsqsum].V = m.stats[xsqsum].V.(float64) + (xf * xf)
+
+	m.addData(xf, 0)
+	return nil
 }
 
-func (m *Machine) RemoveX(x *Value) {
+func (m *Machine) RemoveX(x *Value) error {
 	if m.stats == nil || m.stats[sumn] == nil {
-		return
+		return errNoStats
+	}
+
+	xf, ok := x.V.(float64)
+
+	if !ok {
+		return fmt.Errorf("invalid operand x=%#v", x.V)
 	}
 
-	xf := x.V.(float64)
+	if err := m.removeData(xf, 0); err != nil {
+		return err
+	}
 
 	m.stats[sumn].V = m.stats[sumn].V.(float64) - 1
 	m.stats[xsum].V = m.stats[xsum].V.(float64) - xf
 	m.stats[xsqsum].V = m.stats[xsqsum].V.(float64) - (xf * xf)
+
+	return nil
 }
 
 func (m *Machine) SetFree() {
@@ -244,6 +283,14 @@ func (m *Machine) SetEngineering(d uint) {
 	m.digits = d
 }
 
+func (m *Machine) SetRatio() {
+	m.disp = ratio
+}
+
+func (m *Machine) SetMixed() {
+	m.disp = mixed
+}
+
 func (m *Machine) SetRadians() {
 	m.mode = radians
 }
@@ -80,3 +80,50 @@ func TestConstants(t *testing.T) {
 		t.Errorf("e is wrong: %s", sEps)
 	}
 }
+
+func TestStringRangeKeepsStack(t *testing.T) {
+	m := New(os.Stdout)
+
+	tests := [][]Expr{
+		{String("abc"), Number(0), Number(1e20), Substring},
+		{String("a"), String("b"), Number(1e20), String("-"), Join},
+	}
+
+	for _, e := range tests {
+		m.stack = nil
+
+		if _, err := m.Eval(0, e); err == nil {
+			t.Errorf("%d: no error", len(e))
+		}
+
+		if l := len(m.stack); l != len(e)-1 {
+			t.Errorf("%d: stack has %d items", len(e), l)
+		}
+	}
+}
+
+func TestRemoveMissingData(t *testing.T) {
+	m := New(os.Stdout)
+
+	if _, err := m.Eval(0, []Expr{Number(1), StatsOpRm}); err != errNoStats {
+		t.Errorf("no stats: wrong error %v", err)
+	}
+
+	m.stack = nil
+
+	if _, err := m.Eval(0, []Expr{Number(4.63), Number(0), StatsOpAdd, Number(5.78), Number(20), StatsOpAdd}); err != nil {
+		t.Fatalf("sum: %s", err)
+	}
+
+	if _, err := m.Eval(0, []Expr{Number(1), Number(2), StatsOpRm}); err != errNoPoint {
+		t.Errorf("remove: wrong error %v", err)
+	}
+
+	if n := m.stats[sumn].V.(float64); n != 2 || len(m.data) != 2 {
+		t.Errorf("remove: %v sums but %d points", n, len(m.data))
+	}
+
+	if s := m.stats[xsum].V.(float64); s != 20 {
+		t.Errorf("remove: sum of x is %v", s)
+	}
+}
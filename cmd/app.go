@@ -17,7 +17,11 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
-const pname = "oak"
+const (
+	pname     = "oak"
+	prompt    = "> "
+	continued = "..> "
+)
 
 // runApp creates and runs the app given the arguments.
 func runApp(args []string, version string, input io.Reader, output, errout io.Writer) int {
@@ -158,7 +162,7 @@ func (a *app) fromReadline(home string) error {
 		Stdin:                  readline.NewCancelableStdin(a.stdIn),
 		Stdout:                 a.stdOut,
 		Stderr:                 a.errOut,
-		Prompt:                 "> ",
+		Prompt:                 prompt,
 		HistoryFile:            path.Join(home, ".oakhist"),
 		HistoryLimit:           50,
 		DisableAutoSaveHistory: false,
@@ -174,19 +178,47 @@ func (a *app) fromReadline(home string) error {
 
 	defer rl.Close()
 
+	// we keep one parser for the whole session so that
+	// a word definition may span more than one line
+
+	p := oak.NewParser(a.machine, nil, a.stdOut, il, a.debug)
+
 	for {
 		line, err := rl.Readline()
 
+		if err == readline.ErrInterrupt && p.Defining() {
+			// ctrl-C abandons a partial definition
+
+			p.Abandon()
+			rl.SetPrompt(prompt)
+			continue
+		}
+
 		if err != nil { // io.EOF
 			break
 		}
 
-		c := oak.ScanConfig{Base: 0, Interactive: true}
+		c := oak.ScanConfig{Interactive: true}
 		b := bytes.NewBufferString(line)
-		s := oak.NewScanner(c, pname, b)
-		p := oak.NewParser(a.machine, s, a.stdOut, il, a.debug)
+
+		p.SetScanner(oak.NewScanner(c, pname, b))
 
 		e, _, _ := p.Line()
+
+		if p.Defining() {
+			// anything before the definition is evaluated,
+			// but there's no result until it's complete
+
+			if _, err := a.machine.Eval(il, e); err != nil {
+				fmt.Fprintln(a.stdOut, err)
+			}
+
+			rl.SetPrompt(continued)
+			continue
+		}
+
+		rl.SetPrompt(prompt)
+
 		i, err := a.machine.Eval(il, e)
 
 		if err == io.EOF { // bye
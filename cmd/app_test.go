@@ -30,6 +30,7 @@ type subTest struct {
 	wanted    []string
 	file      bool
 	immediate bool
+	complete  bool // wanted is all the output
 }
 
 func (s subTest) run(t *testing.T) {
@@ -94,6 +95,9 @@ func (s subTest) run(t *testing.T) {
 		}
 	}
 
+	if s.complete && buff.Len() > 0 {
+		t.Errorf("unwanted output %q", buff.String())
+	}
 }
 
 func TestApp(t *testing.T) {
@@ -130,6 +134,36 @@ func TestApp(t *testing.T) {
 				"2: 8.0",
 			},
 		},
+		{
+			name: "fromReadLineMultiLine",
+			input: `2 :f
+3 *
+;
+f
+`,
+			options:  "",
+			complete: true,
+			wanted: []string{
+				"1: 2",
+				"2: 6",
+				"Goodbye",
+			},
+		},
+		{
+			// ctrl-C throws away the definition of f,
+			// leaving the 2 before it on the stack
+
+			name:     "fromReadLineInterrupt",
+			input:    "2 :f\n3 *\n\x03f\n3\n",
+			options:  "",
+			complete: true,
+			wanted: []string{
+				"unknown name: f",
+				"1: 2",
+				"2: 3",
+				"Goodbye",
+			},
+		},
 	}
 
 	for _, st := range table {
@@ -0,0 +1,271 @@
+package oak
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+)
+
+// complexOps are the binary operations which
+// also work on complex operands, by name.
+var complexOps = map[string]func(complex128, complex128) complex128{
+	"add": func(y, x complex128) complex128 { return y + x },
+	"sub": func(y, x complex128) complex128 { return y - x },
+	"mul": func(y, x complex128) complex128 { return y * x },
+	"div": func(y, x complex128) complex128 { return y / x },
+	"pow": cmplx.Pow,
+}
+
+// complexFuncs are the functions which also
+// work on a complex operand, by name; their
+// angles are in radians (see applyComplex).
+var complexFuncs = map[string]func(complex128) complex128{
+	"acos":  cmplx.Acos,
+	"acosh": cmplx.Acosh,
+	"alog":  func(x complex128) complex128 { return cmplx.Pow(10, x) },
+	"asin":  cmplx.Asin,
+	"asinh": cmplx.Asinh,
+	"atan":  cmplx.Atan,
+	"atanh": cmplx.Atanh,
+	"cos":   cmplx.Cos,
+	"cosh":  cmplx.Cosh,
+	"cube":  func(x complex128) complex128 { return x * x * x },
+	"exp":   cmplx.Exp,
+	"ln":    cmplx.Log,
+	"log":   cmplx.Log10,
+	"recp":  func(x complex128) complex128 { return 1 / x },
+	"sin":   cmplx.Sin,
+	"sinh":  cmplx.Sinh,
+	"sqr":   func(x complex128) complex128 { return x * x },
+	"sqrt":  cmplx.Sqrt,
+	"tan":   cmplx.Tan,
+	"tanh":  cmplx.Tanh,
+}
+
+var (
+	// MakeComplex pops the imaginary part {x} and
+	// the real part {y} and pushes a complex number.
+	MakeComplex ExprFunc = func(m *Machine) error {
+		y, x, err := m.popReals("cplx")
+
+		if err != nil {
+			return err
+		}
+
+		m.Push(m.makeComplexVal(complex(y, x)))
+		return nil
+	}
+
+	// Rectangular pops an angle {x} (in the current
+	// mode) and magnitude {y} and pushes a complex number.
+	Rectangular ExprFunc = func(m *Machine) error {
+		r, t, err := m.popReals("rect")
+
+		if err != nil {
+			return err
+		}
+
+		m.Push(m.makeComplexVal(cmplx.Rect(r, m.fromAngle(t))))
+		return nil
+	}
+
+	// Polar pops a complex number and pushes its magnitude
+	// and then its angle (in the current mode).
+	Polar ExprFunc = func(m *Machine) error {
+		z, err := m.popComplex("polar")
+
+		if err != nil {
+			return err
+		}
+
+		r, t := cmplx.Polar(z)
+
+		m.Push(m.makeFloatVal(r))
+		m.Push(m.makeFloatVal(m.toAngle(t)))
+		return nil
+	}
+
+	RealPart = ComplexOp("re", func(m *Machine, z complex128) Value {
+		return m.makeFloatVal(real(z))
+	})
+
+	ImagPart = ComplexOp("im", func(m *Machine, z complex128) Value {
+		return m.makeFloatVal(imag(z))
+	})
+
+	Conjugate = ComplexOp("conj", func(m *Machine, z complex128) Value {
+		return m.makeComplexVal(cmplx.Conj(z))
+	})
+
+	Argument = ComplexOp("arg", func(m *Machine, z complex128) Value {
+		return m.makeFloatVal(m.toAngle(cmplx.Phase(z)))
+	})
+)
+
+// ComplexOp returns an expression which pops a number
+// (treated as complex, even if it's real) and pushes the
+// result of the function.
+func ComplexOp(op string, f func(*Machine, complex128) Value) ExprFunc {
+	return func(m *Machine) error {
+		z, err := m.popComplex(op)
+
+		if err != nil {
+			return err
+		}
+
+		m.Push(f(m, z))
+		return nil
+	}
+}
+
+// popReals removes two real numbers y and x from the stack.
+func (m *Machine) popReals(op string) (float64, float64, error) {
+	if len(m.stack) < 2 {
+		return 0, 0, errUnderflow
+	}
+
+	x := m.PopX()
+	y := m.Pop()
+
+	a, ok1 := y.float()
+	b, ok2 := x.float()
+
+	if !ok1 || !ok2 {
+		return 0, 0, fmt.Errorf("%s: invalid operands y=%#v, x=%#v", op, y.V, x.V)
+	}
+
+	return a, b, nil
+}
+
+// popComplex removes a number from the top of stack
+// as a complex number.
+func (m *Machine) popComplex(op string) (complex128, error) {
+	if len(m.stack) < 1 {
+		return 0, errUnderflow
+	}
+
+	x := m.PopX()
+
+	if z, ok := x.complex(); ok {
+		return z, nil
+	}
+
+	return 0, fmt.Errorf("%s: invalid operand x=%#v", op, x.V)
+}
+
+// toAngle converts an angle in radians to the current mode.
+func (m *Machine) toAngle(t float64) float64 {
+	if m.mode == degrees {
+		return t * 180 / math.Pi
+	}
+
+	return t
+}
+
+// fromAngle converts an angle in the current mode to radians.
+func (m *Machine) fromAngle(t float64) float64 {
+	if m.mode == degrees {
+		return t * math.Pi / 180
+	}
+
+	return t
+}
+
+// binaryComplex applies the named operation when either
+// operand is complex (the other may be real).
+func (m *Machine) binaryComplex(op string, y, x *Value) (Value, error) {
+	f, ok := complexOps[op]
+
+	if !ok {
+		return Value{}, fmt.Errorf("%s: invalid complex operands y=%#v, x=%#v", op, y.V, x.V)
+	}
+
+	a, ok1 := y.complex()
+	b, ok2 := x.complex()
+
+	if !ok1 || !ok2 {
+		return Value{}, fmt.Errorf("%s: mismatched operands y=%#v, x=%#v", op, y.V, x.V)
+	}
+
+	return m.makeComplexVal(f(a, b)), nil
+}
+
+// unaryComplex applies the named function to a complex
+// operand; the absolute value is a real number.
+func (m *Machine) unaryComplex(op string, x *Value) (Value, error) {
+	z := x.V.(complex128)
+
+	if op == "abs" {
+		return m.makeFloatVal(cmplx.Abs(z)), nil
+	}
+
+	if r, ok := applyComplex(op, x.M, z); ok {
+		return m.makeComplexVal(r), nil
+	}
+
+	return Value{}, fmt.Errorf("%s: invalid complex operand x=%#v", op, x.V)
+}
+
+// complexResult finds the complex value for a real function
+// which had no real result (e.g., the square root of -1),
+// if complex mode is set and there's a complex version.
+func (m *Machine) complexResult(op string, x *Value, a, r float64) (Value, bool) {
+	if !m.cplx || !math.IsNaN(r) || math.IsNaN(a) {
+		return Value{}, false
+	}
+
+	if z, ok := applyComplex(op, x.M, complex(a, 0)); ok {
+		return m.makeComplexVal(z), true
+	}
+
+	return Value{}, false
+}
+
+// applyComplex applies the named complex function, if there
+// is one; angles are converted as for real trigonometry, if
+// the operand's mode is degrees.
+func applyComplex(op string, angle mode, z complex128) (complex128, bool) {
+	f, ok := complexFuncs[op]
+
+	if !ok {
+		return 0, false
+	}
+
+	if angle == degrees && isTrig(op) {
+		z *= math.Pi / 180
+	}
+
+	r := f(z)
+
+	if angle == degrees && isInverseTrig(op) {
+		r *= 180 / math.Pi
+	}
+
+	return r, true
+}
+
+// complex returns the value of a number as a complex
+// number, or false if the value isn't a number.
+func (v Value) complex() (complex128, bool) {
+	if v.T == complexer {
+		return v.V.(complex128), true
+	}
+
+	if f, ok := v.float(); ok {
+		return complex(f, 0), true
+	}
+
+	return 0, false
+}
+
+// formatComplex shows a complex number with both parts
+// in the current display mode, e.g., (1.00-2.00i).
+func (m *Machine) formatComplex(z complex128) string {
+	r, i := m.formatFloat(real(z)), m.formatFloat(imag(z))
+
+	if i[0] != '-' {
+		i = "+" + i
+	}
+
+	return "(" + r + i + "i)"
+}
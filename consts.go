@@ -0,0 +1,132 @@
+package oak
+
+import (
+	"fmt"
+	"strings"
+)
+
+// constant is a named value with its unit (if any),
+// from CODATA 2018 for the physical constants.
+type constant struct {
+	name string
+	desc string
+	v    float64
+	u    string
+}
+
+// constantTable is kept in order by name (ignoring case)
+// so that it lists nicely.
+var constantTable = []constant{
+	{"a_0", "Bohr radius", 5.29177210903e-11, "m"},
+	{"alpha", "fine-structure constant", 7.2973525693e-3, ""},
+	{"atm", "standard atmosphere", 101325, "Pa"},
+	{"c", "speed of light in vacuum", 299792458, "m/s"},
+	{"e", "elementary charge", 1.602176634e-19, "C"},
+	{"eps_0", "vacuum electric permittivity", 8.8541878128e-12, "C/V/m"},
+	{"euler", "Euler-Mascheroni constant", 0.57721566490153286, ""},
+	{"F", "Faraday constant", 96485.33212, "C/mol"},
+	{"G", "Newtonian constant of gravitation", 6.67430e-11, "m^3/kg/s^2"},
+	{"g_n", "standard acceleration of gravity", 9.80665, "m/s^2"},
+	{"h", "Planck constant", 6.62607015e-34, "J*s"},
+	{"hbar", "reduced Planck constant", 1.054571817e-34, "J*s"},
+	{"k_B", "Boltzmann constant", 1.380649e-23, "J/K"},
+	{"ln2", "natural logarithm of 2", 0.69314718055994531, ""},
+	{"m_e", "electron mass", 9.1093837015e-31, "kg"},
+	{"m_n", "neutron mass", 1.67492749804e-27, "kg"},
+	{"m_p", "proton mass", 1.67262192369e-27, "kg"},
+	{"m_u", "atomic mass constant", 1.66053906660e-27, "kg"},
+	{"mu_0", "vacuum magnetic permeability", 1.25663706212e-6, "N/A^2"},
+	{"N_A", "Avogadro constant", 6.02214076e23, "1/mol"},
+	{"phi", "golden ratio", 1.6180339887498948, ""},
+	{"pi", "ratio of a circle's circumference to its diameter", 3.1415926535897932, ""},
+	{"R", "molar gas constant", 8.314462618, "J/mol/K"},
+	{"R_inf", "Rydberg constant", 10973731.568160, "1/m"},
+	{"sigma", "Stefan-Boltzmann constant", 5.670374419e-8, "W/m^2/K^4"},
+	{"sqrt2", "square root of 2", 1.4142135623730951, ""},
+}
+
+var (
+	// Constant pops the name of a constant (a string)
+	// and pushes its value, with its unit.
+	Constant ExprFunc = func(m *Machine) error {
+		if len(m.stack) < 1 {
+			return errUnderflow
+		}
+
+		x := m.Pop()
+
+		if x.T != stringer {
+			return fmt.Errorf("const: invalid operand x=%#v", x.V)
+		}
+
+		c, ok := lookupConstant(x.V.(string))
+
+		if !ok {
+			return fmt.Errorf("const: unknown constant %q", x.V)
+		}
+
+		u, _ := parseUnit(c.u)
+
+		m.Push(m.makeUnitVal(c.v, u))
+		return nil
+	}
+
+	// Constants lists all the constants.
+	Constants ExprFunc = func(m *Machine) error {
+		m.listConstants(constantTable)
+		return nil
+	}
+
+	// FindConstants pops a string and lists the constants
+	// whose name or description contains it (ignoring case).
+	FindConstants ExprFunc = func(m *Machine) error {
+		if len(m.stack) < 1 {
+			return errUnderflow
+		}
+
+		x := m.Pop()
+
+		if x.T != stringer {
+			return fmt.Errorf("const?: invalid operand x=%#v", x.V)
+		}
+
+		r := matchConstants(x.V.(string))
+
+		if len(r) == 0 {
+			return fmt.Errorf("const?: no constant matches %q", x.V)
+		}
+
+		m.listConstants(r)
+		return nil
+	}
+)
+
+func lookupConstant(s string) (constant, bool) {
+	for _, c := range constantTable {
+		if c.name == s {
+			return c, true
+		}
+	}
+
+	return constant{}, false
+}
+
+func matchConstants(s string) []constant {
+	var r []constant
+
+	s = strings.ToLower(s)
+
+	for _, c := range constantTable {
+		if strings.Contains(strings.ToLower(c.name), s) || strings.Contains(strings.ToLower(c.desc), s) {
+			r = append(r, c)
+		}
+	}
+
+	return r
+}
+
+func (m *Machine) listConstants(cs []constant) {
+	for _, c := range cs {
+		fmt.Fprintf(m.output, "%-6s %-26s %s\n", c.name, strings.TrimSpace(fmt.Sprint(c.v)+" "+c.u), c.desc)
+	}
+}
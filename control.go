@@ -0,0 +1,361 @@
+package oak
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// defaultLoops is the maximum number of loop iterations
+// in any one line (counting all the loops, nested or not)
+// unless otherwise configured.
+const defaultLoops = 1000000
+
+var errLoopLimit = errors.New("loop limit exceeded")
+
+// block represents a control structure (e.g., if/else/then)
+// while it's being compiled inside a word definition; the
+// parser keeps a stack of them so that they may be nested.
+type block struct {
+	kind  string
+	body  []Expr
+	alt   []Expr
+	other bool // collecting the alternate list
+}
+
+// add appends an expression to whichever list
+// of the block is currently being collected.
+func (b *block) add(e Expr) {
+	if b.other {
+		b.alt = append(b.alt, e)
+	} else {
+		b.body = append(b.body, e)
+	}
+}
+
+// isControl reports whether the identifier is
+// a control word, which must be compiled.
+func isControl(s string) bool {
+	switch s {
+	case "if", "else", "then",
+		"do", "loop", "i", "j",
+		"begin", "until", "while", "repeat":
+		return true
+	}
+
+	return false
+}
+
+// control handles a control word in a word definition; it
+// opens or switches a block, returning nil, or closes a block
+// and returns the expression that runs it (to be added to the
+// enclosing block or the word itself).
+func (p *Parser) control(s string) (Expr, error) {
+	if !p.compile {
+		return nil, fmt.Errorf("%s: only valid in a word", s)
+	}
+
+	switch s {
+	case "if":
+		p.blocks = append(p.blocks, &block{kind: s})
+		return nil, nil
+
+	case "else":
+		b := p.block()
+
+		if b == nil || b.kind != "if" || b.other {
+			return nil, fmt.Errorf("else without if")
+		}
+
+		b.other = true
+		return nil, nil
+
+	case "then":
+		b := p.block()
+
+		if b == nil || b.kind != "if" {
+			return nil, fmt.Errorf("then without if")
+		}
+
+		p.blocks = p.blocks[:len(p.blocks)-1]
+		return Conditional(b.body, b.alt), nil
+
+	case "do", "begin":
+		p.blocks = append(p.blocks, &block{kind: s})
+		return nil, nil
+
+	case "loop":
+		b := p.block()
+
+		if b == nil || b.kind != "do" {
+			return nil, fmt.Errorf("loop without do")
+		}
+
+		p.blocks = p.blocks[:len(p.blocks)-1]
+		return CountedLoop(b.body), nil
+
+	case "until":
+		b := p.block()
+
+		if b == nil || b.kind != "begin" || b.other {
+			return nil, fmt.Errorf("until without begin")
+		}
+
+		p.blocks = p.blocks[:len(p.blocks)-1]
+		return UntilLoop(b.body), nil
+
+	case "while":
+		b := p.block()
+
+		if b == nil || b.kind != "begin" || b.other {
+			return nil, fmt.Errorf("while without begin")
+		}
+
+		b.other = true
+		return nil, nil
+
+	case "repeat":
+		b := p.block()
+
+		if b == nil || b.kind != "begin" || !b.other {
+			return nil, fmt.Errorf("repeat without while")
+		}
+
+		p.blocks = p.blocks[:len(p.blocks)-1]
+		return WhileLoop(b.body, b.alt), nil
+
+	case "i", "j":
+		// the index is only valid inside a do loop,
+		// and j refers to the next outer do loop
+
+		n := 1
+
+		if s == "j" {
+			n = 2
+		}
+
+		for _, b := range p.blocks {
+			if b.kind == "do" {
+				n--
+			}
+		}
+
+		if n > 0 {
+			return nil, fmt.Errorf("%s outside of do loop", s)
+		}
+
+		return LoopIndex(s), nil
+	}
+
+	return nil, errUnknown
+}
+
+// block returns the innermost open block, if any.
+func (p *Parser) block() *block {
+	if l := len(p.blocks); l > 0 {
+		return p.blocks[l-1]
+	}
+
+	return nil
+}
+
+// Conditional pops a flag and runs the first list
+// of expressions if it's true, otherwise the second
+// (which may be empty if there was no else).
+func Conditional(t, f []Expr) ExprFunc {
+	return func(m *Machine) error {
+		c, err := m.PopFlag("if")
+
+		if err != nil {
+			return err
+		}
+
+		if c {
+			return evalList(m, t)
+		}
+
+		return evalList(m, f)
+	}
+}
+
+// CountedLoop pops a starting index {x} and a limit {y}
+// and runs the list of expressions once for each index
+// from the start up to (but not including) the limit.
+func CountedLoop(body []Expr) ExprFunc {
+	return func(m *Machine) error {
+		if len(m.stack) < 2 {
+			return errUnderflow
+		}
+
+		x := m.Pop()
+		y := m.Pop()
+
+		start, ok1 := x.float()
+		limit, ok2 := y.float()
+
+		if !ok1 || !ok2 {
+			return fmt.Errorf("do: invalid operands y=%#v, x=%#v", y.V, x.V)
+		}
+
+		l := len(m.loops)
+		m.loops = append(m.loops, start)
+
+		defer func() { m.loops = m.loops[:l] }()
+
+		for m.loops[l] < limit {
+			if err := m.countLoop(); err != nil {
+				return err
+			}
+
+			if err := evalList(m, body); err != nil {
+				return err
+			}
+
+			m.loops[l]++
+		}
+
+		return nil
+	}
+}
+
+// UntilLoop runs the list of expressions and then pops
+// a flag, repeating until the flag is true.
+func UntilLoop(body []Expr) ExprFunc {
+	return func(m *Machine) error {
+		for {
+			if err := m.countLoop(); err != nil {
+				return err
+			}
+
+			if err := evalList(m, body); err != nil {
+				return err
+			}
+
+			done, err := m.PopFlag("until")
+
+			if err != nil {
+				return err
+			}
+
+			if done {
+				return nil
+			}
+		}
+	}
+}
+
+// WhileLoop runs the first list of expressions and then
+// pops a flag; while it's true, it runs the second list
+// and repeats, otherwise it stops.
+func WhileLoop(test, body []Expr) ExprFunc {
+	return func(m *Machine) error {
+		for {
+			if err := m.countLoop(); err != nil {
+				return err
+			}
+
+			if err := evalList(m, test); err != nil {
+				return err
+			}
+
+			more, err := m.PopFlag("while")
+
+			if err != nil {
+				return err
+			}
+
+			if !more {
+				return nil
+			}
+
+			if err := evalList(m, body); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// LoopIndex pushes the index of the innermost do loop
+// (for i) or the one just outside it (for j).
+func LoopIndex(s string) ExprFunc {
+	n := 1
+
+	if s == "j" {
+		n = 2
+	}
+
+	return func(m *Machine) error {
+		l := len(m.loops)
+
+		if l < n {
+			return fmt.Errorf("%s outside of do loop", s)
+		}
+
+		m.Push(m.makeFloatVal(m.loops[l-n]))
+		return nil
+	}
+}
+
+// countLoop counts one more loop iteration, failing once
+// the line has run more than the maximum; the count is for
+// all loops, so nested loops (or recursive words with loops)
+// can't multiply it.
+func (m *Machine) countLoop() error {
+	limit := defaultLoops
+
+	if m.maxLoop != 0 {
+		limit = int(m.maxLoop)
+	}
+
+	if m.iters >= limit {
+		return errLoopLimit
+	}
+
+	m.iters++
+	return nil
+}
+
+// PopFlag removes the top of stack and returns its
+// truth value: any non-zero number is true.
+func (m *Machine) PopFlag(op string) (bool, error) {
+	if len(m.stack) < 1 {
+		return false, errUnderflow
+	}
+
+	x := m.Pop() // not a calculation, so no PopX
+
+	switch x.T {
+	case floater:
+		return x.V.(float64) != 0, nil
+
+	case integer:
+		return x.V.(uint) != 0, nil
+
+	case bigint:
+		return x.V.(*big.Int).Sign() != 0, nil
+
+	case rational:
+		return x.V.(*big.Rat).Sign() != 0, nil
+
+	case bigfloat:
+		return x.V.(*big.Float).Sign() != 0, nil
+	}
+
+	return false, fmt.Errorf("%s: invalid flag %#v", op, x.V)
+}
+
+// evalList runs a list of expressions in order,
+// stopping at the first failure.
+func evalList(m *Machine, exprs []Expr) error {
+	for _, e := range exprs {
+		if e == nil {
+			return fmt.Errorf("found nil expression")
+		}
+
+		if err := e.Eval(m); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,172 @@
+package oak
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// dataPoint is one set of values entered with ∑+, kept
+// (along with the sums in the stats registers) for
+// statistics which depend on the order of the data.
+type dataPoint struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y,omitempty"`
+}
+
+// each of these works on a list (a vector) if there's one
+// on the stack, or else on the stored data points for y and x
+
+var (
+	// Median pushes the median of a list, or
+	// the medians of the data for y and x.
+	Median ExprFunc = func(m *Machine) error {
+		return m.pushOrdered(0, func(s []float64) float64 {
+			return percentile(s, 0.5)
+		})
+	}
+
+	// Quantile pops a probability {x} and pushes the quantile
+	// of a list, or of the data for y and x, for that probability.
+	Quantile ExprFunc = func(m *Machine) error {
+		if len(m.stack) < 1 {
+			return errUnderflow
+		}
+
+		x := m.Top()
+		p, ok := x.float()
+
+		if !ok {
+			return fmt.Errorf("quantile: invalid operand x=%#v", x.V)
+		}
+
+		if !(p >= 0 && p <= 1) {
+			return fmt.Errorf("quantile: invalid probability %v", p)
+		}
+
+		return m.pushOrdered(1, func(s []float64) float64 {
+			return percentile(s, p)
+		})
+	}
+
+	// InterQuartile pushes the interquartile range (the 75%
+	// quantile less the 25% quantile) of a list, or of the
+	// data for y and x.
+	InterQuartile ExprFunc = func(m *Machine) error {
+		return m.pushOrdered(0, func(s []float64) float64 {
+			return percentile(s, 0.75) - percentile(s, 0.25)
+		})
+	}
+
+	// SortData pushes a list, or the data for y and
+	// x as vectors, in increasing order.
+	SortData ExprFunc = func(m *Machine) error {
+		lists, err := m.orderedData(0)
+
+		if err != nil {
+			return err
+		}
+
+		for _, s := range lists {
+			m.Push(m.makeVectorVal(s))
+		}
+
+		return nil
+	}
+)
+
+// orderedData returns a sorted copy of the list below the
+// top n items on the stack (removing them and the list),
+// or else sorted copies of the data for y and x.
+func (m *Machine) orderedData(n int) ([][]float64, error) {
+	l := len(m.stack)
+
+	if l > n && m.stack[l-1-n].T == vector {
+		s := append([]float64(nil), m.stack[l-1-n].V.([]float64)...)
+
+		if len(s) == 0 {
+			return nil, errNoStats
+		}
+
+		sort.Float64s(s)
+
+		m.PopX()
+		m.stack = m.stack[:l-1-n]
+
+		return [][]float64{s}, nil
+	}
+
+	if len(m.data) == 0 {
+		return nil, errNoStats
+	}
+
+	if n > 0 {
+		m.PopX()
+		m.stack = m.stack[:l-n]
+	}
+
+	ys, xs := m.sortedData()
+	return [][]float64{ys, xs}, nil
+}
+
+func (m *Machine) pushOrdered(n int, f func([]float64) float64) error {
+	lists, err := m.orderedData(n)
+
+	if err != nil {
+		return err
+	}
+
+	for _, s := range lists {
+		m.Push(m.makeFloatVal(f(s)))
+	}
+
+	return nil
+}
+
+// addData keeps a data point as it's entered.
+func (m *Machine) addData(x, y float64) {
+	m.data = append(m.data, dataPoint{X: x, Y: y})
+}
+
+// removeData removes the last matching data point,
+// or fails if there isn't one.
+func (m *Machine) removeData(x, y float64) error {
+	for i := len(m.data) - 1; i >= 0; i-- {
+		if d := m.data[i]; d.X == x && d.Y == y {
+			m.data = append(m.data[:i], m.data[i+1:]...)
+			return nil
+		}
+	}
+
+	return errNoPoint
+}
+
+// sortedData returns separate (sorted) copies of the
+// data points for y and x.
+func (m *Machine) sortedData() ([]float64, []float64) {
+	ys := make([]float64, len(m.data))
+	xs := make([]float64, len(m.data))
+
+	for i, d := range m.data {
+		ys[i], xs[i] = d.Y, d.X
+	}
+
+	sort.Float64s(ys)
+	sort.Float64s(xs)
+
+	return ys, xs
+}
+
+// percentile interpolates between the sorted values
+// nearest the probability p, so that 0 is the least
+// value and 1 is the greatest.
+func percentile(s []float64, p float64) float64 {
+	h := p * float64(len(s)-1)
+	i := math.Floor(h)
+
+	if int(i) >= len(s)-1 {
+		return s[len(s)-1]
+	}
+
+	return s[int(i)] + (h-i)*(s[int(i)+1]-s[int(i)])
+}
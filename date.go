@@ -0,0 +1,281 @@
+package oak
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// dates are kept as a wall-clock time in UTC, so there's
+// no time zone or daylight savings time to get in the way
+// of calendar arithmetic (every day is 24 hours long)
+
+const day = 24 * time.Hour
+
+// maxDays limits the number of days added to a date,
+// so that the result is (about) within 10,000 years.
+const maxDays = 10000 * 366
+
+var (
+	dateLiteral = regexp.MustCompile(`^\d{4}-\d\d-\d\d`)
+	dateLayouts = []string{"2006-01-02", "2006-01-02T15:04", "2006-01-02T15:04:05"}
+)
+
+var (
+	// ToDate pops a string (e.g., "2026-10-17" or
+	// "2026-10-17T13:45") and pushes it as a date.
+	ToDate ExprFunc = func(m *Machine) error {
+		if len(m.stack) < 1 {
+			return errUnderflow
+		}
+
+		x := m.PopX()
+
+		switch x.T {
+		case datetime:
+			m.Push(*x)
+			return nil
+
+		case stringer:
+			t, err := parseDate(strings.TrimSpace(x.V.(string)))
+
+			if err != nil {
+				return fmt.Errorf("date: %s %q", err, x.V)
+			}
+
+			m.Push(m.makeDateVal(t))
+			return nil
+		}
+
+		return fmt.Errorf("date: invalid operand x=%#v", x.V)
+	}
+
+	// Today pushes the current date.
+	Today ExprFunc = func(m *Machine) error {
+		m.Push(m.makeDateVal(wallClock(time.Now()).Truncate(day)))
+		return nil
+	}
+
+	// Now pushes the current date and time.
+	Now ExprFunc = func(m *Machine) error {
+		m.Push(m.makeDateVal(wallClock(time.Now()).Truncate(time.Second)))
+		return nil
+	}
+
+	// DayOfWeek pops a date and pushes its day of the
+	// week, from 1 (Monday) to 7 (Sunday).
+	DayOfWeek ExprFunc = func(m *Machine) error {
+		if len(m.stack) < 1 {
+			return errUnderflow
+		}
+
+		x := m.PopX()
+
+		if x.T != datetime {
+			return fmt.Errorf("dow: invalid operand x=%#v", x.V)
+		}
+
+		d := x.V.(time.Time).Weekday()
+
+		if d == time.Sunday {
+			d = 7
+		}
+
+		m.Push(m.makeFloatVal(float64(d)))
+		return nil
+	}
+
+	// BusinessDays pops two dates and pushes the number of
+	// weekdays (Monday to Friday) after the first up to and
+	// including the second (negative if it's earlier).
+	BusinessDays ExprFunc = func(m *Machine) error {
+		if len(m.stack) < 2 {
+			return errUnderflow
+		}
+
+		x := m.PopX()
+		y := m.Pop()
+
+		if x.T != datetime || y.T != datetime {
+			return fmt.Errorf("bdays: invalid operands y=%#v, x=%#v", y.V, x.V)
+		}
+
+		a, b := y.V.(time.Time).Truncate(day), x.V.(time.Time).Truncate(day)
+		sign := 1.0
+
+		if b.Before(a) {
+			a, b, sign = b, a, -1
+		}
+
+		m.Push(m.makeFloatVal(sign * float64(weekdays(a, b))))
+		return nil
+	}
+
+	// AddBusinessDays pops a number of weekdays {x} and
+	// a date {y} and pushes the date that many weekdays
+	// later (or earlier, if negative).
+	AddBusinessDays ExprFunc = func(m *Machine) error {
+		if len(m.stack) < 2 {
+			return errUnderflow
+		}
+
+		x := m.PopX()
+		y := m.Pop()
+
+		n, ok := x.float()
+
+		if !ok || y.T != datetime || n != math.Trunc(n) || !(math.Abs(n) <= maxDays) {
+			return fmt.Errorf("addbd: invalid operands y=%#v, x=%#v", y.V, x.V)
+		}
+
+		t, step, sign := y.V.(time.Time), day, 1
+
+		if n < 0 {
+			n, step, sign = -n, -day, -1
+		}
+
+		// every week has five weekdays, wherever it starts,
+		// so we skip whole weeks and count the last few days
+
+		if w := math.Floor((n - 1) / 5); w > 0 {
+			t = t.AddDate(0, 0, sign*7*int(w))
+			n -= 5 * w
+		}
+
+		for n > 0 {
+			t = t.Add(step)
+
+			if isWeekday(t) {
+				n--
+			}
+		}
+
+		m.Push(m.makeDateVal(t))
+		return nil
+	}
+)
+
+func (m *Machine) makeDateVal(t time.Time) Value {
+	return Value{T: datetime, M: m.mode, V: t, m: m}
+}
+
+// DateLiteral pushes a date entered as a literal.
+func DateLiteral(t time.Time) ExprFunc {
+	return func(m *Machine) error {
+		m.Push(m.makeDateVal(t))
+		return nil
+	}
+}
+
+// parseDate reads a date, possibly with a time,
+// in ISO 8601 format (without a time zone).
+func parseDate(s string) (time.Time, error) {
+	for _, l := range dateLayouts {
+		if t, err := time.Parse(l, s); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid date")
+}
+
+// wallClock returns the local time as if it were UTC.
+func wallClock(t time.Time) time.Time {
+	y, mo, d := t.Date()
+	h, mi, s := t.Clock()
+
+	return time.Date(y, mo, d, h, mi, s, t.Nanosecond(), time.UTC)
+}
+
+func isWeekday(t time.Time) bool {
+	d := t.Weekday()
+	return d != time.Saturday && d != time.Sunday
+}
+
+// weekdays counts the weekdays after a up to and
+// including b, where a isn't after b.
+func weekdays(a, b time.Time) int {
+	n := int(b.Sub(a) / day)
+	r := n / 7 * 5
+
+	// the remaining days are less than a week
+
+	for t := a.AddDate(0, 0, n/7*7); t.Before(b); {
+		t = t.Add(day)
+
+		if isWeekday(t) {
+			r++
+		}
+	}
+
+	return r
+}
+
+// binaryDate does arithmetic with dates: a date minus a
+// date is the number of days between them, and a number
+// of days may be added to or subtracted from a date.
+func (m *Machine) binaryDate(op string, y, x *Value) (Value, error) {
+	switch {
+	case op == "sub" && x.T == datetime && y.T == datetime:
+		d := y.V.(time.Time).Sub(x.V.(time.Time))
+		return m.makeFloatVal(float64(d) / float64(day)), nil
+
+	case op == "add" || op == "sub":
+		t, n := y, x
+
+		if op == "add" && x.T == datetime {
+			t, n = x, y
+		}
+
+		f, ok := n.float()
+
+		if !ok || t.T != datetime {
+			break
+		}
+
+		if !(math.Abs(f) <= maxDays) {
+			return Value{}, fmt.Errorf("%s: invalid number of days %v", op, n.V)
+		}
+
+		if op == "sub" {
+			f = -f
+		}
+
+		// whole days are added on the calendar, so that
+		// the time of day stays exact
+
+		w := math.Trunc(f)
+		d := t.V.(time.Time).AddDate(0, 0, int(w))
+
+		return m.makeDateVal(d.Add(time.Duration((f - w) * float64(day)).Round(time.Millisecond))), nil
+	}
+
+	return Value{}, fmt.Errorf("%s: mismatched operands y=%#v, x=%#v", op, y.V, x.V)
+}
+
+// formatDate shows a date, along with the time of day if
+// it's not midnight; in a display mode other than free, the
+// seconds have as many digits as the display (up to 9).
+func (m *Machine) formatDate(t time.Time) string {
+	if t.Equal(t.Truncate(day)) {
+		return t.Format("2006-01-02")
+	}
+
+	if m.disp == free {
+		return t.Format("2006-01-02T15:04:05.999999999")
+	}
+
+	l := "2006-01-02T15:04:05"
+
+	if d := m.digits; d > 0 {
+		if d > 9 {
+			d = 9
+		}
+
+		l += "." + strings.Repeat("0", int(d))
+	}
+
+	return t.Format(l)
+}
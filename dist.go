@@ -0,0 +1,382 @@
+package oak
+
+import (
+	"fmt"
+	"math"
+)
+
+// a distribution has a density (or mass) function and a
+// cumulative distribution function, each of a value and the
+// distribution's parameters, and their inverse (quantile)
+
+type distribution struct {
+	params   int
+	discrete bool
+	lower    float64 // the least value (or -Inf)
+	pdf      func(x float64, a []float64) float64
+	cdf      func(x float64, a []float64) float64
+}
+
+var distributions = map[string]distribution{
+	"norm": {params: 2, lower: math.Inf(-1), pdf: normPDF, cdf: normCDF},
+	"t":    {params: 1, lower: math.Inf(-1), pdf: studentPDF, cdf: studentCDF},
+	"chi":  {params: 1, pdf: chiPDF, cdf: chiCDF},
+	"f":    {params: 2, pdf: fisherPDF, cdf: fisherCDF},
+	"bin":  {params: 2, discrete: true, pdf: binomialPDF, cdf: binomialCDF},
+	"poi":  {params: 1, discrete: true, pdf: poissonPDF, cdf: poissonCDF},
+}
+
+// DistributionOp pops the parameters of a distribution and
+// then a value (pushed before them), and pushes the density
+// (pdf), cumulative probability (cdf), or, for a probability,
+// the value with that cumulative probability (inv).
+func DistributionOp(name, kind string) ExprFunc {
+	return func(m *Machine) error {
+		op := name + kind
+		d := distributions[name]
+		a, err := m.popFloats(op, d.params+1)
+
+		if err != nil {
+			return err
+		}
+
+		x, a := a[0], a[1:]
+
+		switch kind {
+		case "pdf":
+			m.Push(m.makeFloatVal(d.pdf(x, a)))
+
+		case "cdf":
+			m.Push(m.makeFloatVal(d.cdf(x, a)))
+
+		case "inv":
+			r, err := d.quantile(x, a)
+
+			if err != nil {
+				return fmt.Errorf("%s: %w", op, err)
+			}
+
+			m.Push(m.makeFloatVal(r))
+		}
+
+		return nil
+	}
+}
+
+// TTest pops a confidence level (e.g., 0.95) and pushes the
+// lower {y} and upper {x} limits of the confidence interval
+// for the mean of x in the stats registers, using Student's t.
+var TTest ExprFunc = func(m *Machine) error {
+	if m.stats == nil || m.stats[sumn].V == nil || m.stats[sumn].V.(float64) == 0 {
+		return errNoStats
+	}
+
+	c, err := m.popFloats("ttest", 1)
+
+	if err != nil {
+		return err
+	}
+
+	if c[0] <= 0 || c[0] >= 1 {
+		return fmt.Errorf("ttest: invalid confidence level %v", c[0])
+	}
+
+	n := m.stats[sumn].V.(float64)
+	xs := m.stats[xsum].V.(float64)
+	xsq := m.stats[xsqsum].V.(float64)
+
+	if n < 2 {
+		return fmt.Errorf("ttest: too few data points")
+	}
+
+	t, err := distributions["t"].quantile((1+c[0])/2, []float64{n - 1})
+
+	if err != nil {
+		return fmt.Errorf("ttest: %w", err)
+	}
+
+	sd := math.Sqrt((n*xsq - xs*xs) / (n * (n - 1)))
+	h := t * sd / math.Sqrt(n)
+
+	m.Push(m.makeFloatVal(xs/n - h))
+	m.Push(m.makeFloatVal(xs/n + h))
+
+	return nil
+}
+
+// popFloats removes n numbers from the stack, returning
+// them in the order they were pushed.
+func (m *Machine) popFloats(op string, n int) ([]float64, error) {
+	if len(m.stack) < n {
+		return nil, errUnderflow
+	}
+
+	r := make([]float64, n)
+
+	for i := n - 1; i >= 0; i-- {
+		var v *Value
+
+		if i == n-1 {
+			v = m.PopX()
+		} else {
+			v = m.Pop()
+		}
+
+		f, ok := v.float()
+
+		if !ok {
+			return nil, fmt.Errorf("%s: invalid operand %#v", op, v.V)
+		}
+
+		r[i] = f
+	}
+
+	return r, nil
+}
+
+// quantile finds the least value whose cumulative
+// probability is at least p.
+func (d distribution) quantile(p float64, a []float64) (float64, error) {
+	if p < 0 || p > 1 || math.IsNaN(d.cdf(d.lower, a)) {
+		return math.NaN(), nil
+	}
+
+	if d.discrete {
+		// we double k until we're past the value, and
+		// then bisect over the integers to find it
+
+		q := p * (1 - 1e-12)
+
+		if d.cdf(0, a) >= q {
+			return 0, nil
+		}
+
+		lo, hi := 0.0, 1.0
+
+		for d.cdf(hi, a) < q {
+			if lo, hi = hi, hi*2; hi > 1e15 {
+				return 0, errNoSolution
+			}
+		}
+
+		for hi-lo > 1 {
+			if k := math.Floor((lo + hi) / 2); d.cdf(k, a) >= q {
+				hi = k
+			} else {
+				lo = k
+			}
+		}
+
+		return hi, nil
+	}
+
+	switch p {
+	case 0:
+		return d.lower, nil
+	case 1:
+		return math.Inf(1), nil
+	}
+
+	// find an interval with the value in it, and then
+	// let the root finder do the rest
+
+	lo, hi := -1.0, 1.0
+
+	if !math.IsInf(d.lower, -1) {
+		lo = d.lower
+	}
+
+	for i := 0; d.cdf(lo, a) > p; i++ {
+		if lo *= 2; i > 1000 {
+			return 0, errNoSolution
+		}
+	}
+
+	for i := 0; d.cdf(hi, a) < p; i++ {
+		if hi *= 2; i > 1000 {
+			return 0, errNoSolution
+		}
+	}
+
+	f := func(x float64) (float64, error) {
+		return d.cdf(x, a) - p, nil
+	}
+
+	return solve(f, lo, hi)
+}
+
+func normPDF(x float64, a []float64) float64 {
+	mu, sd := a[0], a[1]
+
+	if sd <= 0 {
+		return math.NaN()
+	}
+
+	z := (x - mu) / sd
+	return math.Exp(-z*z/2) / (sd * math.Sqrt(2*math.Pi))
+}
+
+func normCDF(x float64, a []float64) float64 {
+	mu, sd := a[0], a[1]
+
+	if sd <= 0 {
+		return math.NaN()
+	}
+
+	return math.Erfc(-(x-mu)/(sd*math.Sqrt2)) / 2
+}
+
+func studentPDF(x float64, a []float64) float64 {
+	v := a[0]
+
+	if v <= 0 {
+		return math.NaN()
+	}
+
+	l := lgamma((v+1)/2) - lgamma(v/2) - math.Log(v*math.Pi)/2
+	return math.Exp(l - (v+1)/2*math.Log1p(x*x/v))
+}
+
+func studentCDF(x float64, a []float64) float64 {
+	v := a[0]
+
+	if v <= 0 {
+		return math.NaN()
+	}
+
+	if math.IsInf(x, 0) {
+		return math.Max(0, math.Copysign(1, x))
+	}
+
+	p := betaInc(v/2, 0.5, v/(v+x*x)) / 2
+
+	if x > 0 {
+		return 1 - p
+	}
+
+	return p
+}
+
+func chiPDF(x float64, a []float64) float64 {
+	k := a[0]
+
+	switch {
+	case k <= 0:
+		return math.NaN()
+	case x < 0:
+		return 0
+	case x == 0:
+		// the density is infinite for k < 2
+		return chiPDF(math.SmallestNonzeroFloat64, a)
+	}
+
+	return math.Exp((k/2-1)*math.Log(x) - x/2 - k/2*math.Ln2 - lgamma(k/2))
+}
+
+func chiCDF(x float64, a []float64) float64 {
+	k := a[0]
+
+	switch {
+	case k <= 0:
+		return math.NaN()
+	case x <= 0:
+		return 0
+	}
+
+	return gammaInc(k/2, x/2)
+}
+
+func fisherPDF(x float64, a []float64) float64 {
+	d1, d2 := a[0], a[1]
+
+	switch {
+	case d1 <= 0 || d2 <= 0:
+		return math.NaN()
+	case x < 0:
+		return 0
+	case x == 0:
+		return fisherPDF(math.SmallestNonzeroFloat64, a)
+	}
+
+	l := (d1*math.Log(d1*x)+d2*math.Log(d2)-(d1+d2)*math.Log(d1*x+d2))/2 - math.Log(x)
+	return math.Exp(l - math.Log(beta(d1/2, d2/2)))
+}
+
+func fisherCDF(x float64, a []float64) float64 {
+	d1, d2 := a[0], a[1]
+
+	switch {
+	case d1 <= 0 || d2 <= 0:
+		return math.NaN()
+	case x <= 0:
+		return 0
+	case math.IsInf(x, 1):
+		return 1
+	}
+
+	return betaInc(d1/2, d2/2, d1*x/(d1*x+d2))
+}
+
+func binomialPDF(k float64, a []float64) float64 {
+	n, p := a[0], a[1]
+
+	switch {
+	case n < 0 || n != math.Trunc(n) || p < 0 || p > 1:
+		return math.NaN()
+	case k < 0 || k > n || k != math.Trunc(k):
+		return 0
+	case p == 0 || p == 1:
+		if k == n*p {
+			return 1
+		}
+
+		return 0
+	}
+
+	l := lgamma(n+1) - lgamma(k+1) - lgamma(n-k+1)
+	return math.Exp(l + k*math.Log(p) + (n-k)*math.Log1p(-p))
+}
+
+func binomialCDF(k float64, a []float64) float64 {
+	n, p := a[0], a[1]
+
+	switch {
+	case n < 0 || n != math.Trunc(n) || p < 0 || p > 1:
+		return math.NaN()
+	case k < 0:
+		return 0
+	case k >= n:
+		return 1
+	case p == 0 || p == 1:
+		return 1 - p
+	}
+
+	k = math.Floor(k)
+	return betaInc(n-k, k+1, 1-p)
+}
+
+func poissonPDF(k float64, a []float64) float64 {
+	l := a[0]
+
+	switch {
+	case l <= 0:
+		return math.NaN()
+	case k < 0 || k != math.Trunc(k):
+		return 0
+	}
+
+	return math.Exp(k*math.Log(l) - l - lgamma(k+1))
+}
+
+func poissonCDF(k float64, a []float64) float64 {
+	l := a[0]
+
+	switch {
+	case l <= 0:
+		return math.NaN()
+	case k < 0:
+		return 0
+	}
+
+	return gammaIncComp(math.Floor(k)+1, l)
+}
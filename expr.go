@@ -3,7 +3,10 @@ package oak
 import (
 	"fmt"
 	"math"
+	"math/big"
 	"math/bits"
+	"strings"
+	"time"
 )
 
 const (
@@ -48,35 +51,14 @@ func BinaryOp(op string, f func(float64, float64) float64) ExprFunc {
 			return fmt.Errorf("%s: empty stack", op)
 		}
 
-		switch x.T {
-		case floater:
-			switch y.T {
-			case floater:
-				s := f(y.V.(float64), x.V.(float64))
-				m.Push(m.makeFloatVal(s))
-				return nil
-
-			case integer:
-				s := f(float64(y.V.(uint)), x.V.(float64))
-				m.Push(m.makeFloatVal(s))
-				return nil
-			}
-
-		case integer:
-			switch y.T {
-			case floater:
-				s := f(y.V.(float64), float64(x.V.(uint)))
-				m.Push(m.makeFloatVal(s))
-				return nil
+		r, err := m.binary(op, f, y, x)
 
-			case integer:
-				s := f(float64(y.V.(uint)), float64(x.V.(uint)))
-				m.Push(m.makeFloatVal(s))
-				return nil
-			}
+		if err != nil {
+			return err
 		}
 
-		return fmt.Errorf("%s: mismatched operands y=%#v, x=%#v", op, y.V, x.V)
+		m.Push(r)
+		return nil
 	}
 }
 
@@ -89,8 +71,6 @@ func BinarySaveOp(op string, f func(float64, float64) float64) ExprFunc {
 		// same as above, but don't pop the y value;
 		// used e.g. for the percent/delta percent ops
 
-		// TODO - refactor out the common parts ...
-
 		x := m.PopX()
 		y := m.Top()
 
@@ -98,36 +78,84 @@ func BinarySaveOp(op string, f func(float64, float64) float64) ExprFunc {
 			return fmt.Errorf("%s: empty stack", op)
 		}
 
-		switch x.T {
-		case floater:
-			switch y.T {
-			case floater:
-				s := f(y.V.(float64), x.V.(float64))
-				m.Push(m.makeFloatVal(s))
-				return nil
+		r, err := m.binary(op, f, y, x)
 
-			case integer:
-				s := f(float64(y.V.(uint)), x.V.(float64))
-				m.Push(m.makeFloatVal(s))
-				return nil
-			}
+		if err != nil {
+			return err
+		}
 
-		case integer:
-			switch y.T {
-			case floater:
-				s := f(y.V.(float64), float64(x.V.(uint)))
-				m.Push(m.makeFloatVal(s))
-				return nil
+		m.Push(r)
+		return nil
+	}
+}
 
-			case integer:
-				s := f(float64(y.V.(uint)), float64(x.V.(uint)))
-				m.Push(m.makeFloatVal(s))
-				return nil
-			}
+// binary applies the function to the operands y and x, which
+// may be numbers, vectors, or matrices (where a number is
+// broadcast over each element of the vector or matrix).
+func (m *Machine) binary(op string, f func(float64, float64) float64, y, x *Value) (Value, error) {
+	if x.T == matrix || y.T == matrix {
+		return m.binaryMatrix(op, f, y, x)
+	}
+
+	if x.T == complexer || y.T == complexer {
+		return m.binaryComplex(op, y, x)
+	}
+
+	if x.T == datetime || y.T == datetime {
+		return m.binaryDate(op, y, x)
+	}
+
+	if x.T == quantity || y.T == quantity {
+		return m.binaryUnit(op, f, y, x)
+	}
+
+	if x.T == stringer || y.T == stringer {
+		return m.binaryString(op, y, x)
+	}
+
+	// a number is applied to each element of a vector
+	// as a float, even if it's exact
+
+	if x.T == vector || y.T == vector {
+		return m.binaryVector(op, f, y, x)
+	}
+
+	if x.T == bigfloat || y.T == bigfloat {
+		return m.binaryBigFloat(op, f, y, x)
+	}
+
+	if x.T == rational || y.T == rational {
+		return m.binaryRat(op, f, y, x)
+	}
+
+	if x.T == bigint || y.T == bigint {
+		return m.binaryBig(op, f, y, x)
+	}
+
+	if x.T == integer && y.T == integer && m.base != base10 {
+		if r, ok, err := m.binaryInt(op, y.V.(uint), x.V.(uint)); ok {
+			return r, err
 		}
+	}
 
-		return fmt.Errorf("%s: mismatched operands y=%#v, x=%#v", op, y.V, x.V)
+	a, ok1 := y.float()
+	b, ok2 := x.float()
+
+	if !ok1 || !ok2 {
+		return Value{}, fmt.Errorf("%s: mismatched operands y=%#v, x=%#v", op, y.V, x.V)
 	}
+
+	r := f(a, b)
+
+	// in complex mode, e.g., (-8)**(1/3) has a complex result
+
+	if m.cplx && math.IsNaN(r) && !math.IsNaN(a) && !math.IsNaN(b) {
+		if g, ok := complexOps[op]; ok {
+			return m.makeComplexVal(g(complex(a, 0), complex(b, 0))), nil
+		}
+	}
+
+	return m.makeFloatVal(r), nil
 }
 
 func UnaryOp(op string, f func(float64) float64) ExprFunc {
@@ -142,22 +170,52 @@ func UnaryOp(op string, f func(float64) float64) ExprFunc {
 			return fmt.Errorf("%s: empty stack", op)
 		}
 
-		switch x.T {
-		case floater:
-			s := f(x.V.(float64))
-			m.Push(m.makeFloatVal(s))
-			return nil
+		r, err := m.unary(op, f, x)
 
-		case integer:
-			s := f(float64(x.V.(uint)))
-			m.Push(m.makeFloatVal(s))
-			return nil
+		if err != nil {
+			return err
 		}
 
-		return fmt.Errorf("%s: invalid operand x=%#v", op, x.V)
+		m.Push(r)
+		return nil
 	}
 }
 
+// unary applies the function to the operand x, which may
+// be a number or a vector or matrix (element by element).
+func (m *Machine) unary(op string, f func(float64) float64, x *Value) (Value, error) {
+	switch x.T {
+	case vector:
+		return m.unaryVector(f, x), nil
+	case matrix:
+		return m.unaryMatrix(f, x), nil
+	case complexer:
+		return m.unaryComplex(op, x)
+	case bigint:
+		return m.unaryBig(op, f, x), nil
+	case rational:
+		return m.unaryRat(op, f, x), nil
+	case bigfloat:
+		return m.unaryBigFloat(op, f, x), nil
+	case quantity:
+		return m.unaryUnit(op, f, x)
+	}
+
+	a, ok := x.float()
+
+	if !ok {
+		return Value{}, fmt.Errorf("%s: invalid operand x=%#v", op, x.V)
+	}
+
+	r := f(a)
+
+	if z, ok := m.complexResult(op, x, a, r); ok {
+		return z, nil
+	}
+
+	return m.makeFloatVal(r), nil
+}
+
 func TrigonometryOp(op string, f func(float64) float64) ExprFunc {
 	return func(m *Machine) error {
 		if len(m.stack) < 1 {
@@ -170,31 +228,25 @@ func TrigonometryOp(op string, f func(float64) float64) ExprFunc {
 			return fmt.Errorf("%s: empty stack", op)
 		}
 
-		switch x.T {
-		case floater:
-			var s = x.V.(float64)
+		// the angle is converted from degrees
+		// if that's the operand's mode
 
+		g := func(s float64) float64 {
 			if x.M == degrees {
 				s *= math.Pi / 180
 			}
 
-			s = f(s)
-			m.Push(m.makeFloatVal(s))
-			return nil
+			return f(s)
+		}
 
-		case integer:
-			var s = float64(x.V.(uint))
+		r, err := m.unary(op, g, x)
 
-			if x.M == degrees {
-				s *= math.Pi / 180
-			}
-
-			s = f(s)
-			m.Push(m.makeFloatVal(s))
-			return nil
+		if err != nil {
+			return err
 		}
 
-		return fmt.Errorf("%s: invalid operand x=%#v", op, x.V)
+		m.Push(r)
+		return nil
 	}
 }
 
@@ -210,37 +262,31 @@ func InverseTrigOp(op string, f func(float64) float64) ExprFunc {
 			return fmt.Errorf("%s: empty stack", op)
 		}
 
-		switch x.T {
-		case floater:
-			var s = x.V.(float64)
+		// the angle is converted to degrees
+		// if that's the operand's mode
 
+		g := func(s float64) float64 {
 			s = f(s)
 
 			if x.M == degrees {
 				s *= 180 / math.Pi
 			}
 
-			m.Push(m.makeFloatVal(s))
-			return nil
-
-		case integer:
-			var s = float64(x.V.(uint))
+			return s
+		}
 
-			s = f(s)
+		r, err := m.unary(op, g, x)
 
-			if x.M == degrees {
-				s *= 180 / math.Pi
-			}
-
-			m.Push(m.makeFloatVal(s))
-			return nil
+		if err != nil {
+			return err
 		}
 
-		return fmt.Errorf("%s: invalid operand x=%#v", op, x.V)
+		m.Push(r)
+		return nil
 	}
 }
 
-func BinaryBitwiseOp(op string, f func(y, x uint) uint) ExprFunc {
+func BinaryBitwiseOp(op string, f func(s *intState, y, x uint) uint) ExprFunc {
 	return func(m *Machine) error {
 		if len(m.stack) < 2 {
 			return errUnderflow
@@ -254,17 +300,28 @@ func BinaryBitwiseOp(op string, f func(y, x uint) uint) ExprFunc {
 		}
 
 		if x.T == integer && y.T == integer {
-			r := f(y.V.(uint), x.V.(uint))
+			r := f(&m.ints, y.V.(uint), x.V.(uint))
 
 			m.Push(m.makeIntVal(r))
 			return nil
 		}
 
+		if x.T == bigint || y.T == bigint {
+			r, err := m.bitwiseBig(op, y, x)
+
+			if err != nil {
+				return err
+			}
+
+			m.Push(r)
+			return nil
+		}
+
 		return fmt.Errorf("%s: mismatched operands y=%#v, x=%#v", op, y.V, x.V)
 	}
 }
 
-func UnaryBitwiseOp(op string, f func(x uint) uint) ExprFunc {
+func UnaryBitwiseOp(op string, f func(s *intState, x uint) uint) ExprFunc {
 	return func(m *Machine) error {
 		if len(m.stack) < 1 {
 			return errUnderflow
@@ -276,32 +333,210 @@ func UnaryBitwiseOp(op string, f func(x uint) uint) ExprFunc {
 			return fmt.Errorf("%s: empty stack", op)
 		}
 
+		if x.T == bigint {
+			r, err := m.unaryBitwiseBig(op, x)
+
+			if err != nil {
+				return err
+			}
+
+			m.Push(r)
+			return nil
+		}
+
 		if x.T != integer {
 			return fmt.Errorf("%s: invalid operand %#v", op, x)
 		}
 
-		r := f(x.V.(uint))
+		r := f(&m.ints, x.V.(uint))
 
 		m.Push(m.makeIntVal(r))
 		return nil
 	}
 }
 
+// BitOp is a bitwise operation on bit x (from 0) of y,
+// which fails if x isn't a bit in the word.
+func BitOp(op string, f func(s *intState, y, x uint) uint) ExprFunc {
+	g := BinaryBitwiseOp(op, f)
+
+	return func(m *Machine) error {
+		if x := m.Top(); x != nil && x.T == integer && x.V.(uint) >= m.ints.bits() {
+			return fmt.Errorf("%s: invalid bit number x=%#v", op, x.V)
+		}
+
+		return g(m)
+	}
+}
+
+// CompareOp compares y to x and pushes a flag with the result
+// of the given test on that comparison (-1, 0, +1, or unordered).
+func CompareOp(op string, f func(c int) bool) ExprFunc {
+	return func(m *Machine) error {
+		if len(m.stack) < 2 {
+			return errUnderflow
+		}
+
+		x := m.PopX()
+		y := m.Pop()
+
+		c, err := compare(op, y, x)
+
+		if err != nil {
+			return err
+		}
+
+		m.Push(m.makeFlagVal(f(c)))
+		return nil
+	}
+}
+
+// unordered is the result of comparing values which are
+// neither less than, equal to, nor greater than each other,
+// e.g., NaN (with anything) or unequal complex numbers.
+const unordered = 2
+
+// compare returns -1, 0, or +1 as y is less than, equal to,
+// or greater than x, or else unordered; strings compare only
+// with strings, integers are compared exactly (without using
+// floats), and complex numbers may only be tested for equality.
+func compare(op string, y, x *Value) (int, error) {
+	switch {
+	case x.T == complexer || y.T == complexer:
+		a, ok1 := y.complex()
+		b, ok2 := x.complex()
+
+		if !ok1 || !ok2 || (op != "eq" && op != "ne") {
+			break
+		}
+
+		if a == b {
+			return 0, nil
+		}
+
+		return unordered, nil
+
+	case x.T == integer && y.T == integer:
+		a, b := y.V.(uint), x.V.(uint)
+
+		if y.m != nil {
+			return y.m.ints.compare(a, b), nil
+		}
+
+		switch {
+		case a < b:
+			return -1, nil
+		case a > b:
+			return 1, nil
+		}
+
+		return 0, nil
+
+	case x.T == stringer && y.T == stringer:
+		return strings.Compare(y.V.(string), x.V.(string)), nil
+
+	case x.T == quantity || y.T == quantity:
+		return compareUnit(op, y, x)
+
+	case x.T == datetime && y.T == datetime:
+		a, b := y.V.(time.Time), x.V.(time.Time)
+
+		switch {
+		case a.Before(b):
+			return -1, nil
+		case a.After(b):
+			return 1, nil
+		}
+
+		return 0, nil
+
+	case x.T == bigfloat || y.T == bigfloat:
+		a, ok1 := y.bigFloat(0)
+		b, ok2 := x.bigFloat(0)
+
+		if ok1 && ok2 {
+			return a.Cmp(b), nil
+		}
+
+	case x.T == rational || y.T == rational:
+		a, ok1 := y.rat()
+		b, ok2 := x.rat()
+
+		if ok1 && ok2 {
+			return a.Cmp(b), nil
+		}
+
+	case x.T == bigint || y.T == bigint:
+		a, ok1 := y.bigInt()
+		b, ok2 := x.bigInt()
+
+		if ok1 && ok2 {
+			return a.Cmp(b), nil
+		}
+	}
+
+	a, ok1 := y.float()
+	b, ok2 := x.float()
+
+	if !ok1 || !ok2 {
+		return 0, fmt.Errorf("%s: mismatched operands y=%#v, x=%#v", op, y.V, x.V)
+	}
+
+	switch {
+	case a < b:
+		return -1, nil
+	case a > b:
+		return 1, nil
+	case a != b:
+		return unordered, nil
+	}
+
+	return 0, nil
+}
+
+// LogicalOp pops two flags and pushes a flag
+// with the result of the logical operation.
+func LogicalOp(op string, f func(y, x bool) bool) ExprFunc {
+	return func(m *Machine) error {
+		if len(m.stack) < 2 {
+			return errUnderflow
+		}
+
+		x, err := m.PopFlag(op)
+
+		if err != nil {
+			return err
+		}
+
+		y, err := m.PopFlag(op)
+
+		if err != nil {
+			return err
+		}
+
+		m.Push(m.makeFlagVal(f(y, x)))
+		return nil
+	}
+}
+
 var (
 	StatsOpAdd ExprFunc = func(m *Machine) error {
 		l := len(m.stack)
 
 		switch {
 		case l > 1:
-			x := m.stack[l-1]
-			y := m.stack[l-2]
+			x, ok1 := m.stack[l-1].toFloat()
+			y, ok2 := m.stack[l-2].toFloat()
+
+			if !ok1 || !ok2 {
+				return fmt.Errorf("invalid operands y=%#v, x=%#v", m.stack[l-2].V, m.stack[l-1].V)
+			}
 
-			if x.T != floater || y.T != floater {
-				return fmt.Errorf("invalid operands y=%#v, x=%#v", y.V, x.V)
+			if err := m.SumXY(x, y); err != nil {
+				return err
 			}
 
-			m.x = x
-			m.SumXY(x, y)
+			m.x = m.stack[l-1]
 
 			n := *m.stats[sumn] // must copy value
 			m.stack[l-1] = &n
@@ -309,15 +544,17 @@ var (
 			return nil
 
 		case l == 1:
-			x := m.stack[l-1]
+			x, ok := m.stack[l-1].toFloat()
 
-			if x.T != floater {
-				return fmt.Errorf("invalid operand x=%#v", x.V)
+			if !ok {
+				return fmt.Errorf("invalid operand x=%#v", m.stack[l-1].V)
 			}
 
-			m.x = x
+			if err := m.SumX(x); err != nil {
+				return err
+			}
 
-			m.SumX(x)
+			m.x = m.stack[l-1]
 
 			n := *m.stats[sumn] // must copy value
 			m.stack[l-1] = &n
@@ -333,15 +570,18 @@ var (
 
 		switch {
 		case l > 1:
-			x := m.stack[l-1]
-			y := m.stack[l-2]
+			x, ok1 := m.stack[l-1].toFloat()
+			y, ok2 := m.stack[l-2].toFloat()
+
+			if !ok1 || !ok2 {
+				return fmt.Errorf("invalid operands y=%#v, x=%#v", m.stack[l-2].V, m.stack[l-1].V)
+			}
 
-			if x.T != floater || y.T != floater {
-				return fmt.Errorf("invalid operands y=%#v, x=%#v", y.V, x.V)
+			if err := m.RemoveXY(x, y); err != nil {
+				return err
 			}
 
-			m.x = x
-			m.RemoveXY(x, y)
+			m.x = m.stack[l-1]
 
 			n := *m.stats[sumn] // must copy value
 			m.stack[l-1] = &n
@@ -349,15 +589,17 @@ var (
 			return nil
 
 		case l == 1:
-			x := m.stack[l-1]
+			x, ok := m.stack[l-1].toFloat()
 
-			if x.T != floater {
-				return fmt.Errorf("invalid operand x=%#v", x.V)
+			if !ok {
+				return fmt.Errorf("invalid operand x=%#v", m.stack[l-1].V)
 			}
 
-			m.x = x
+			if err := m.RemoveX(x); err != nil {
+				return err
+			}
 
-			m.RemoveX(x)
+			m.x = m.stack[l-1]
 
 			n := *m.stats[sumn] // must copy value
 			m.stack[l-1] = &n
@@ -455,13 +697,12 @@ var (
 		}
 
 		x := m.PopX()
+		xf, ok := x.float()
 
-		if x.T != floater {
+		if !ok {
 			return fmt.Errorf("invalid operand %#v", x.V)
 		}
 
-		xf := x.V.(float64)
-
 		n := m.stats[sumn].V.(float64)
 		xs := m.stats[xsum].V.(float64)
 		ys := m.stats[ysum].V.(float64)
@@ -494,6 +735,17 @@ var (
 				t.V = int(float64(t.V.(uint)) * 180 / math.Pi)
 				t.M = degrees
 				return nil
+
+			case bigfloat:
+				t.V = toDegrees(t.V.(*big.Float), m.precision())
+				t.M = degrees
+				return nil
+
+			case bigint, rational:
+				f, _ := t.float()
+				t.V, t.T = f*180/math.Pi, floater
+				t.M = degrees
+				return nil
 			}
 
 			return fmt.Errorf("hex: invalid operand x=%#v", t.V)
@@ -516,6 +768,17 @@ var (
 				t.V = int(float64(t.V.(uint)) * math.Pi / 180)
 				t.M = radians
 				return nil
+
+			case bigfloat:
+				t.V = toRadians(t.V.(*big.Float), m.precision())
+				t.M = radians
+				return nil
+
+			case bigint, rational:
+				f, _ := t.float()
+				t.V, t.T = f*math.Pi/180, floater
+				t.M = radians
+				return nil
 			}
 
 			return fmt.Errorf("hex: invalid operand x=%#v", t.V)
@@ -525,35 +788,77 @@ var (
 	}
 )
 
-func ArithmeticShift(y, x uint) uint {
-	if x >= 64 {
+// ShiftLeft shifts y left by x bits within the word;
+// the carry is the last bit shifted out.
+func ShiftLeft(s *intState, y, x uint) uint {
+	n := s.bits()
+
+	switch {
+	case x == 0:
+		return y
+	case x > n:
+		s.carry = false
 		return 0
 	}
 
-	const high = 1 << 63
-	var mask uint
+	s.carry = y>>(n-x)&1 == 1
+	return y << x
+}
 
-	if y&high == high {
-		mask = ^uint(0) << (64 - x)
+// ShiftRight shifts y right by x bits, filling with 0s;
+// the carry is the last bit shifted out.
+func ShiftRight(s *intState, y, x uint) uint {
+	switch {
+	case x == 0:
+		return y
+	case x > s.bits():
+		s.carry = false
+		return 0
 	}
 
-	return y>>x | mask
+	s.carry = y>>(x-1)&1 == 1
+	return y >> x
 }
 
-func MaskLeft(x uint) uint {
-	if x >= 64 {
-		return ^uint(0)
+// ArithmeticShift shifts y right by x bits, filling with
+// the sign (top) bit of the word.
+func ArithmeticShift(s *intState, y, x uint) uint {
+	n := s.bits()
+	high := y>>(n-1)&1 == 1
+
+	if x >= n {
+		s.carry = high
+
+		if high {
+			return s.mask()
+		}
+
+		return 0
 	}
 
-	return ^uint(0) << (64 - x)
+	r := ShiftRight(s, y, x)
+
+	if high {
+		r |= s.mask() &^ (s.mask() >> x)
+	}
+
+	return r
 }
 
-func MaskRight(x uint) uint {
-	if x >= 64 {
-		return ^uint(0)
+func MaskLeft(s *intState, x uint) uint {
+	if x >= s.bits() {
+		return s.mask()
 	}
 
-	return ^uint(0) >> (64 - x)
+	return s.mask() &^ (s.mask() >> x)
+}
+
+func MaskRight(s *intState, x uint) uint {
+	if x >= s.bits() {
+		return s.mask()
+	}
+
+	return s.mask() >> (s.bits() - x)
 }
 
 func Permutation(y, x float64) float64 {
@@ -566,20 +871,41 @@ func Combination(y, x float64) float64 {
 
 var (
 	Add      = BinaryOp("add", func(y, x float64) float64 { return y + x })
-	Multiply = BinaryOp("mul", func(y, x float64) float64 { return y * x })
+	Multiply = MatrixProduct(BinaryOp("mul", func(y, x float64) float64 { return y * x }))
 	Subtract = BinaryOp("sub", func(y, x float64) float64 { return y - x })
 	Divide   = BinaryOp("div", func(y, x float64) float64 { return y / x })
 	Modulo   = BinaryOp("mod", func(y, x float64) float64 { return math.Mod(y, x) })
 	Power    = BinaryOp("pow", func(y, x float64) float64 { return math.Pow(y, x) })
 
-	And        = BinaryBitwiseOp("and", func(y, x uint) uint { return y & x })
-	Or         = BinaryBitwiseOp("or", func(y, x uint) uint { return y | x })
-	Xor        = BinaryBitwiseOp("xor", func(y, x uint) uint { return y ^ x })
-	LeftShift  = BinaryBitwiseOp("shl", func(y, x uint) uint { return y << x })
-	RightShift = BinaryBitwiseOp("shr", func(y, x uint) uint { return y >> x })
+	And        = BinaryBitwiseOp("and", func(_ *intState, y, x uint) uint { return y & x })
+	Or         = BinaryBitwiseOp("or", func(_ *intState, y, x uint) uint { return y | x })
+	Xor        = BinaryBitwiseOp("xor", func(_ *intState, y, x uint) uint { return y ^ x })
+	LeftShift  = BinaryBitwiseOp("shl", ShiftLeft)
+	RightShift = BinaryBitwiseOp("shr", ShiftRight)
 	ArithShift = BinaryBitwiseOp("shr", ArithmeticShift)
 
-	Not = UnaryBitwiseOp("not", func(x uint) uint { return ^x })
+	Not = UnaryBitwiseOp("not", func(_ *intState, x uint) uint { return ^x })
+
+	Equal        = CompareOp("eq", func(c int) bool { return c == 0 })
+	NotEqual     = CompareOp("ne", func(c int) bool { return c != 0 })
+	Less         = CompareOp("lt", func(c int) bool { return c == -1 })
+	LessEqual    = CompareOp("le", func(c int) bool { return c == -1 || c == 0 })
+	Greater      = CompareOp("gt", func(c int) bool { return c == 1 })
+	GreaterEqual = CompareOp("ge", func(c int) bool { return c == 0 || c == 1 })
+
+	LogicalAnd = LogicalOp("and", func(y, x bool) bool { return y && x })
+	LogicalOr  = LogicalOp("or", func(y, x bool) bool { return y || x })
+
+	LogicalNot ExprFunc = func(m *Machine) error {
+		x, err := m.PopFlag("not")
+
+		if err != nil {
+			return err
+		}
+
+		m.Push(m.makeFlagVal(!x))
+		return nil
+	}
 )
 
 // Predefined returns an expression given a named function
@@ -590,28 +916,58 @@ func Predefined(s string) Expr { // nolint:gocyclo
 		return UnaryOp(s, math.Abs)
 	case "acos":
 		return InverseTrigOp(s, math.Acos)
+	case "acosh":
+		return UnaryOp(s, math.Acosh)
 	case "alog":
 		return UnaryOp(s, func(x float64) float64 { return math.Pow(10, x) })
 	case "asin":
 		return InverseTrigOp(s, math.Asin)
+	case "asinh":
+		return UnaryOp(s, math.Asinh)
 	case "atan":
 		return InverseTrigOp(s, math.Atan)
+	case "atanh":
+		return UnaryOp(s, math.Atanh)
+	case "b?":
+		return BitOp(s, TestBit)
+	case "beta":
+		return BinaryOp(s, beta)
+	case "brev":
+		return UnaryBitwiseOp(s, BitReverse)
+	case "bswap":
+		return UnaryBitwiseOp(s, ByteSwap)
+	case "cb":
+		return BitOp(s, ClearBit)
 	case "cbrt":
 		return UnaryOp(s, math.Cbrt)
 	case "ceil":
 		return UnaryOp(s, math.Ceil)
+	case "clz":
+		return UnaryBitwiseOp(s, LeadingZeros)
 	case "comb":
 		return BinaryOp(s, Combination)
 	case "cos":
 		return TrigonometryOp(s, math.Cos)
+	case "cosh":
+		return UnaryOp(s, math.Cosh)
+	case "ctz":
+		return UnaryBitwiseOp(s, TrailingZeros)
 	case "cube":
 		return UnaryOp(s, func(x float64) float64 { return x * x * x })
 	case "deg":
 		return Degrees
 	case "dist":
 		return BinaryOp(s, math.Hypot)
+	case "dms":
+		return DMS
 	case "dperc":
 		return BinarySaveOp(s, func(y, x float64) float64 { return (x - y) / y * 100 })
+	case "erf":
+		return UnaryOp(s, math.Erf)
+	case "erfc":
+		return UnaryOp(s, math.Erfc)
+	case "erfinv":
+		return UnaryOp(s, math.Erfinv)
 	case "exp":
 		return UnaryOp(s, math.Exp)
 	case "fact":
@@ -620,6 +976,30 @@ func Predefined(s string) Expr { // nolint:gocyclo
 		return UnaryOp(s, math.Floor)
 	case "frac":
 		return UnaryOp(s, func(x float64) float64 { return x - math.Trunc(x) })
+	case "gamma":
+		return UnaryOp(s, math.Gamma)
+	case "hms":
+		return UnaryOp(s, toHMS)
+	case "hms+":
+		return BinaryOp(s, func(y, x float64) float64 { return toHMS(fromHMS(y) + fromHMS(x)) })
+	case "hms-":
+		return BinaryOp(s, func(y, x float64) float64 { return toHMS(fromHMS(y) - fromHMS(x)) })
+	case "hr":
+		return UnaryOp(s, fromHMS)
+	case "ibeta":
+		return IncompleteBeta
+	case "igamma":
+		return BinaryOp(s, gammaInc)
+	case "igammac":
+		return BinaryOp(s, gammaIncComp)
+	case "j0":
+		return UnaryOp(s, math.J0)
+	case "j1":
+		return UnaryOp(s, math.J1)
+	case "jn":
+		return BesselOp(s, math.Jn)
+	case "lgamma":
+		return UnaryOp(s, lgamma)
 	case "ln":
 		return UnaryOp(s, math.Log)
 	case "log":
@@ -629,29 +1009,49 @@ func Predefined(s string) Expr { // nolint:gocyclo
 	case "maskr":
 		return UnaryBitwiseOp(s, MaskRight)
 	case "max":
-		return BinaryOp(s, math.Max)
+		return Reduction(s, maxOf, BinaryOp(s, math.Max))
 	case "min":
-		return BinaryOp(s, math.Min)
+		return Reduction(s, minOf, BinaryOp(s, math.Min))
 	case "perc":
 		return BinarySaveOp(s, func(y, x float64) float64 { return y * x / 100 })
 	case "perm":
 		return BinaryOp(s, Permutation)
 	case "popcnt":
-		return UnaryBitwiseOp(s, func(x uint) uint { return uint(bits.OnesCount(x)) })
+		return UnaryBitwiseOp(s, func(_ *intState, x uint) uint { return uint(bits.OnesCount(x)) })
 	case "rad":
 		return Radians
 	case "recp":
 		return UnaryOp(s, func(x float64) float64 { return 1 / x })
+	case "rl":
+		return BinaryBitwiseOp(s, RotateLeft)
+	case "rlc":
+		return BinaryBitwiseOp(s, RotateLeftCarry)
+	case "rr":
+		return BinaryBitwiseOp(s, RotateRight)
+	case "rrc":
+		return BinaryBitwiseOp(s, RotateRightCarry)
+	case "sb":
+		return BitOp(s, SetBit)
 	case "sin":
 		return TrigonometryOp(s, math.Sin)
+	case "sinh":
+		return UnaryOp(s, math.Sinh)
 	case "sqr":
 		return UnaryOp(s, func(x float64) float64 { return x * x })
 	case "sqrt":
 		return UnaryOp(s, math.Sqrt)
 	case "tan":
 		return TrigonometryOp(s, math.Tan)
+	case "tanh":
+		return UnaryOp(s, math.Tanh)
 	case "trunc":
 		return UnaryOp(s, math.Trunc)
+	case "y0":
+		return UnaryOp(s, math.Y0)
+	case "y1":
+		return UnaryOp(s, math.Y1)
+	case "yn":
+		return BesselOp(s, math.Yn)
 	}
 
 	return nil
@@ -125,6 +125,11 @@ func TestUnaryOp(t *testing.T) {
 		{name: "maskr", xt: integer, x: uint(6), ops: []Expr{Predefined("maskr")}, base: base16, want: "0x003f"},
 		{name: "mask65", xt: integer, x: uint(65), ops: []Expr{Predefined("maskr")}, base: base16, want: "0xffffffffffffffff"},
 		{name: "popcnt", xt: integer, x: uint(65535), ops: []Expr{Predefined("popcnt")}, base: base16, want: "0x0010"},
+		{name: "bswap", xt: integer, x: uint(0x0102030405060708), ops: []Expr{Predefined("bswap")}, base: base16,
+			want: "0x807060504030201"},
+		{name: "brev", xt: integer, x: uint(1), ops: []Expr{Predefined("brev")}, base: base16, want: "0x8000000000000000"},
+		{name: "clz", xt: integer, x: uint(0x100), ops: []Expr{Predefined("clz")}, base: base16, want: "0x0037"},
+		{name: "ctz", xt: integer, x: uint(0x100), ops: []Expr{Predefined("ctz")}, base: base16, want: "0x0008"},
 	}
 
 	for _, tt := range table {
@@ -233,6 +238,12 @@ func TestBitwiseOp(t *testing.T) {
 		{name: "shr", x: 3, y: 0b01101001, ops: []Expr{RightShift}, want: "0b00001101"},
 		{name: "asr", x: 3, y: 0xf000000001101001, ops: []Expr{ArithShift},
 			want: "0b1111111000000000000000000000000000000000001000100000001000000000"},
+		{name: "rl", x: 2, y: 0xc000000000000001, ops: []Expr{Predefined("rl")}, want: "0b00000111"},
+		{name: "rr", x: 1, y: 0b00000011, ops: []Expr{Predefined("rr")},
+			want: "0b1000000000000000000000000000000000000000000000000000000000000001"},
+		{name: "sb", x: 7, y: 0b00000011, ops: []Expr{Predefined("sb")}, want: "0b10000011"},
+		{name: "cb", x: 1, y: 0b00000011, ops: []Expr{Predefined("cb")}, want: "0b00000001"},
+		{name: "b?", x: 1, y: 0b00000011, ops: []Expr{Predefined("b?")}, want: "0b00000001"},
 	}
 
 	for _, tt := range table {
@@ -0,0 +1,420 @@
+package oak
+
+import (
+	"fmt"
+	"math"
+)
+
+type freg uint
+
+// the time-value-of-money (TVM) registers
+const (
+	tvmN freg = iota
+	tvmI
+	tvmPV
+	tvmPMT
+	tvmFV
+	nfreg // total number
+)
+
+var tvmNames = [nfreg]string{"n", "rate", "pv", "pmt", "fv"}
+
+// maxPeriods limits the periods used by amort and the
+// count of a cash flow, which are calculated one by one.
+const maxPeriods = 100000
+
+// tvm holds the TVM registers as numbers, with the
+// interest rate as a fraction per period (not a percent).
+type tvm struct {
+	n, i, pv, pmt, fv float64
+	begin             bool
+}
+
+// StoreTVM stores the top of stack in a TVM register,
+// leaving it on the stack (as on the HP 12c).
+func StoreTVM(r freg) ExprFunc {
+	return func(m *Machine) error {
+		f, err := m.topFloat(tvmNames[r])
+
+		if err != nil {
+			return err
+		}
+
+		// the rate must leave something to discount by
+
+		if r == tvmI && !(f > -100) {
+			return fmt.Errorf("rate: invalid interest rate %v", f)
+		}
+
+		if m.tvm == nil {
+			m.initTVM()
+		}
+
+		*m.tvm[r] = Value{T: floater, M: m.mode, V: f, m: m}
+		return nil
+	}
+}
+
+// SolveTVM calculates one TVM register from the other four,
+// stores it, and pushes it.
+func SolveTVM(r freg) ExprFunc {
+	return func(m *Machine) error {
+		op := tvmNames[r] + "?"
+
+		if m.tvm == nil {
+			m.initTVM()
+		}
+
+		t := m.getTVM()
+		f, err := t.solve(r)
+
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		if r == tvmI {
+			f *= 100
+		}
+
+		*m.tvm[r] = Value{T: floater, M: m.mode, V: f, m: m}
+		m.Push(m.makeFloatVal(f))
+		return nil
+	}
+}
+
+// cashFlow is an amount received (or paid, if negative)
+// in each of a number of consecutive periods.
+type cashFlow struct {
+	A float64 `json:"amount"`
+	N uint    `json:"count"`
+}
+
+var (
+	// ClearFinance clears the financial registers.
+	ClearFinance ExprFunc = func(m *Machine) error {
+		m.clearFinance()
+		return nil
+	}
+
+	// CashFlow0 starts a new list of cash flows
+	// with the initial flow (the top of stack).
+	CashFlow0 ExprFunc = func(m *Machine) error {
+		f, err := m.topFloat("cf0")
+
+		if err != nil {
+			return err
+		}
+
+		m.flows = []cashFlow{{A: f, N: 1}}
+		return nil
+	}
+
+	// CashFlowJ adds the next cash flow, which
+	// occurs once unless a count is set with nj.
+	CashFlowJ ExprFunc = func(m *Machine) error {
+		f, err := m.topFloat("cfj")
+
+		if err != nil {
+			return err
+		}
+
+		if len(m.flows) == 0 {
+			return fmt.Errorf("cfj: no initial cash flow")
+		}
+
+		m.flows = append(m.flows, cashFlow{A: f, N: 1})
+		return nil
+	}
+
+	// CashFlowCount sets the number of times
+	// the last cash flow occurs.
+	CashFlowCount ExprFunc = func(m *Machine) error {
+		f, err := m.topFloat("nj")
+
+		if err != nil {
+			return err
+		}
+
+		if len(m.flows) < 2 {
+			return fmt.Errorf("nj: no cash flow")
+		}
+
+		n, ok := m.Top().whole(maxPeriods)
+
+		if !ok || n < 1 {
+			return fmt.Errorf("nj: invalid count %v", f)
+		}
+
+		m.flows[len(m.flows)-1].N = uint(n)
+		return nil
+	}
+
+	// NetPresentValue pushes the NPV of the cash flows,
+	// discounted at the interest rate in the TVM register.
+	NetPresentValue ExprFunc = func(m *Machine) error {
+		if len(m.flows) == 0 {
+			return fmt.Errorf("npv: no cash flows")
+		}
+
+		var i float64
+
+		if m.tvm != nil {
+			i, _ = m.tvm[tvmI].float()
+		}
+
+		m.Push(m.makeFloatVal(npv(m.flows, i/100)))
+		return nil
+	}
+
+	// InternalRate finds the interest rate that makes the NPV
+	// of the cash flows zero, stores it, and pushes it.
+	InternalRate ExprFunc = func(m *Machine) error {
+		if len(m.flows) == 0 {
+			return fmt.Errorf("irr: no cash flows")
+		}
+
+		// there's no solution unless the flows change sign
+
+		var pos, neg bool
+
+		for _, c := range m.flows {
+			pos = pos || c.A > 0
+			neg = neg || c.A < 0
+		}
+
+		if !pos || !neg {
+			return fmt.Errorf("irr: %w", errNoSolution)
+		}
+
+		f := func(x float64) (float64, error) {
+			return npv(m.flows, x/100), nil
+		}
+
+		r, err := solve(f, -99, 100)
+
+		if err == nil && (math.IsNaN(r) || math.IsInf(r, 0)) {
+			err = errNoSolution
+		}
+
+		if err != nil {
+			return fmt.Errorf("irr: %w", err)
+		}
+
+		if m.tvm == nil {
+			m.initTVM()
+		}
+
+		*m.tvm[tvmI] = Value{T: floater, M: m.mode, V: r, m: m}
+		m.Push(m.makeFloatVal(r))
+		return nil
+	}
+
+	// Amortize pops the first and last periods of a range
+	// and pushes the interest and principal paid over that
+	// range and the balance after it, using the TVM registers.
+	Amortize ExprFunc = func(m *Machine) error {
+		if len(m.stack) < 2 {
+			return errUnderflow
+		}
+
+		x := m.Pop()
+		y := m.Pop()
+
+		last, ok1 := x.float()
+		first, ok2 := y.float()
+
+		if !ok1 || !ok2 {
+			return fmt.Errorf("amort: invalid operands y=%#v, x=%#v", y.V, x.V)
+		}
+
+		j, ok1 := y.whole(maxPeriods)
+		k, ok2 := x.whole(maxPeriods)
+
+		if !ok1 || !ok2 || j < 1 || k < j {
+			return fmt.Errorf("amort: invalid range %v-%v", first, last)
+		}
+
+		if m.tvm == nil {
+			m.initTVM()
+		}
+
+		in, pr, b := m.getTVM().amortize(j, k)
+
+		m.Push(m.makeFloatVal(in))
+		m.Push(m.makeFloatVal(pr))
+		m.Push(m.makeFloatVal(b))
+		return nil
+	}
+)
+
+// topFloat returns the top of stack as a float
+// without removing it (as on the HP 12c).
+func (m *Machine) topFloat(op string) (float64, error) {
+	t := m.Top()
+
+	if t == nil {
+		return 0, errUnderflow
+	}
+
+	f, ok := t.float()
+
+	if !ok {
+		return 0, fmt.Errorf("%s: invalid operand x=%#v", op, t.V)
+	}
+
+	return f, nil
+}
+
+// npv discounts the cash flows at a rate i per period.
+func npv(flows []cashFlow, i float64) float64 {
+	var r, t float64
+
+	for _, c := range flows {
+		for j := uint(0); j < c.N; j++ {
+			r += c.A / math.Pow(1+i, t)
+			t++
+		}
+	}
+
+	return r
+}
+
+func (m *Machine) initTVM() {
+	m.tvm = make([]*Value, nfreg)
+
+	for i := range m.tvm {
+		m.tvm[i] = &Value{T: floater, M: m.mode, V: 0.0, m: m}
+	}
+
+	m.linkTVM()
+}
+
+// linkTVM makes the TVM registers readable as variables.
+func (m *Machine) linkTVM() {
+	for i, v := range m.tvm {
+		s := m.makeSymbol("$r_"+tvmNames[i], v)
+		m.vars[s.S] = s
+	}
+}
+
+func (m *Machine) clearFinance() {
+	m.tvm = nil
+	m.flows = nil
+
+	for _, n := range tvmNames {
+		delete(m.vars, "$r_"+n)
+	}
+}
+
+func (m *Machine) getTVM() tvm {
+	var f [nfreg]float64
+
+	for i, v := range m.tvm {
+		f[i], _ = v.float()
+	}
+
+	return tvm{n: f[tvmN], i: f[tvmI] / 100, pv: f[tvmPV], pmt: f[tvmPMT], fv: f[tvmFV], begin: m.begin}
+}
+
+// growth returns (1+i)**n and the sum of that series
+// ((1+i)**n - 1)/i, which is just n if i is zero.
+func (t tvm) growth(i float64) (g, k float64) {
+	if i == 0 {
+		return 1, t.n
+	}
+
+	l := t.n * math.Log1p(i)
+	return math.Exp(l), math.Expm1(l) / i
+}
+
+// due returns the adjustment for payments at the
+// beginning of each period.
+func (t tvm) due(i float64) float64 {
+	if t.begin {
+		return 1 + i
+	}
+
+	return 1
+}
+
+// balance is zero when the registers agree:
+// pv(1+i)**n + pmt(1+is)((1+i)**n - 1)/i + fv = 0
+func (t tvm) balance(i float64) float64 {
+	g, k := t.growth(i)
+	return t.pv*g + t.pmt*t.due(i)*k + t.fv
+}
+
+// solve finds the value of one register (with the
+// interest rate as a fraction, not a percent).
+func (t tvm) solve(r freg) (float64, error) {
+	var f float64
+
+	g, k := t.growth(t.i)
+
+	switch r {
+	case tvmN:
+		if t.i == 0 {
+			f = -(t.pv + t.fv) / t.pmt
+			break
+		}
+
+		p := t.pmt * t.due(t.i) / t.i
+		f = math.Log((p-t.fv)/(p+t.pv)) / math.Log1p(t.i)
+
+	case tvmI:
+		// we search from -99% to +100% per period
+
+		b := func(x float64) (float64, error) {
+			return t.balance(x / 100), nil
+		}
+
+		x, err := solve(b, -99, 100)
+
+		if err != nil {
+			return 0, err
+		}
+
+		f = x / 100
+
+	case tvmPV:
+		f = -(t.fv + t.pmt*t.due(t.i)*k) / g
+
+	case tvmPMT:
+		f = -(t.pv*g + t.fv) / (t.due(t.i) * k)
+
+	case tvmFV:
+		f = -(t.pv*g + t.pmt*t.due(t.i)*k)
+	}
+
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, errNoSolution
+	}
+
+	return f, nil
+}
+
+// amortize returns the interest and principal (negative
+// if paid) over a range of periods, and the balance after
+// the last one; with payments at the beginning of each
+// period, there's no interest in the first payment.
+func (t tvm) amortize(first, last int) (in, pr, b float64) {
+	b = t.pv
+
+	for k := 1; k <= last; k++ {
+		var ik float64
+
+		if !t.begin || k > 1 {
+			ik = -b * t.i
+		}
+
+		pk := t.pmt - ik
+		b += pk
+
+		if k >= first {
+			in += ik
+			pr += pk
+		}
+	}
+
+	return
+}
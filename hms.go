@@ -0,0 +1,111 @@
+package oak
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Times and angles may be written as H.MMSS (e.g., 1.3045 is
+// 1 hour, 30 minutes, and 45 seconds), as on HP calculators.
+
+// DMS converts an angle in degrees-minutes-seconds (D.MMSS)
+// to decimal degrees, which is marked as degrees for the
+// trigonometric functions and the deg/rad conversions.
+var DMS ExprFunc = func(m *Machine) error {
+	if err := UnaryOp("dms", fromHMS).Eval(m); err != nil {
+		return err
+	}
+
+	m.Top().M = degrees
+	return nil
+}
+
+// DegreesLiteral pushes an angle entered in degrees, minutes,
+// and seconds (e.g., 12°30'15"), which is always in degrees.
+func DegreesLiteral(f float64) ExprFunc {
+	return func(m *Machine) error {
+		m.Push(Value{T: floater, M: degrees, V: f, m: m})
+		return nil
+	}
+}
+
+// toHMS converts decimal hours to H.MMSS.
+func toHMS(x float64) float64 {
+	if x < 0 {
+		return -toHMS(-x)
+	}
+
+	// round to a microsecond so (e.g.) 30 minutes
+	// doesn't come out as 29:59.999...
+
+	s := math.Round(x*3600e6) / 1e6
+	h := math.Trunc(s / 3600)
+	s -= h * 3600
+	mm := math.Trunc(s / 60)
+	s -= mm * 60
+
+	return h + mm/100 + s/10000
+}
+
+// fromHMS converts H.MMSS to decimal hours.
+func fromHMS(x float64) float64 {
+	if x < 0 {
+		return -fromHMS(-x)
+	}
+
+	h := math.Trunc(x)
+	f := (x - h) * 100
+	mm := math.Trunc(f + 1e-9)
+	s := (f - mm) * 100
+
+	return h + mm/60 + s/3600
+}
+
+// parseDMS reads an angle in degrees with minutes
+// and seconds, e.g., 12°30'15" or 12°30.5'.
+func parseDMS(s string) (float64, error) {
+	var r float64
+
+	n := s
+	neg := strings.HasPrefix(n, "-")
+
+	if neg {
+		n = n[1:]
+	}
+
+	for _, u := range []struct {
+		mark  string
+		scale float64
+	}{{"°", 1}, {"'", 60}, {`"`, 3600}} {
+		if n == "" {
+			break
+		}
+
+		i := strings.Index(n, u.mark)
+
+		if i < 0 {
+			return 0, fmt.Errorf("invalid angle")
+		}
+
+		f, err := strconv.ParseFloat(n[:i], 64)
+
+		if err != nil || f < 0 || (u.scale > 1 && f >= 60) {
+			return 0, fmt.Errorf("invalid angle")
+		}
+
+		r += f / u.scale
+		n = n[i+len(u.mark):]
+	}
+
+	if n != "" {
+		return 0, fmt.Errorf("invalid angle")
+	}
+
+	if neg {
+		r = -r
+	}
+
+	return r, nil
+}
@@ -360,11 +360,15 @@ func BinaryMathFunc(name string, mf math2Func) ExprFunc {
 		b := m.Pop()
 		a := m.Pop()
 
-		if a.T != floater {
+		af, ok := a.float()
+
+		if !ok {
 			return fmt.Errorf("%s: invalid operand z=%#v", name, a.V)
 		}
 
-		if b.T != floater {
+		bf, ok := b.float()
+
+		if !ok {
 			return fmt.Errorf("%s: invalid operand y=%#v", name, b.V)
 		}
 
@@ -388,15 +392,16 @@ func BinaryMathFunc(name string, mf math2Func) ExprFunc {
 
 			v := m.Pop()
 
-			if v.T != floater {
+			r, ok := v.float()
+
+			if !ok {
 				return 0, fmt.Errorf("%s: invalid result %#v", name, v)
 			}
 
-			r = v.V.(float64)
-			return
+			return r, nil
 		}
 
-		s, err := mf(f, a.V.(float64), b.V.(float64))
+		s, err := mf(f, af, bf)
 
 		if err != nil {
 			return err
@@ -427,7 +432,9 @@ func UnaryMathFunc(name string, mf math1Func) ExprFunc {
 		w := m.Pop()
 		a := m.Pop()
 
-		if a.T != floater {
+		af, ok := a.float()
+
+		if !ok {
 			return fmt.Errorf("%s: invalid operand y=%#v", name, a.V)
 		}
 
@@ -451,15 +458,16 @@ func UnaryMathFunc(name string, mf math1Func) ExprFunc {
 
 			v := m.Pop()
 
-			if v.T != floater {
+			r, ok := v.float()
+
+			if !ok {
 				return 0, fmt.Errorf("%s: invalid result %#v", name, v)
 			}
 
-			r = v.V.(float64)
-			return
+			return r, nil
 		}
 
-		s, err := mf(f, a.V.(float64))
+		s, err := mf(f, af)
 
 		if err != nil {
 			return err
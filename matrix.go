@@ -0,0 +1,524 @@
+package oak
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// maxSweeps limits the Jacobi method for eigenvalues;
+// it normally converges in well under ten sweeps.
+const maxSweeps = 50
+
+var errSingular = errors.New("singular matrix")
+
+var (
+	Transpose ExprFunc = func(m *Machine) error {
+		a, err := m.popMatrix("trn")
+
+		if err != nil {
+			return err
+		}
+
+		m.Push(m.makeMatrixVal(transpose(a)))
+		return nil
+	}
+
+	Determinant ExprFunc = func(m *Machine) error {
+		a, err := m.popSquare("det")
+
+		if err != nil {
+			return err
+		}
+
+		m.Push(m.makeFloatVal(determinant(a)))
+		return nil
+	}
+
+	Inverse ExprFunc = func(m *Machine) error {
+		a, err := m.popSquare("inv")
+
+		if err != nil {
+			return err
+		}
+
+		r, err := inverse(a)
+
+		if err != nil {
+			return fmt.Errorf("inv: %w", err)
+		}
+
+		m.Push(m.makeMatrixVal(r))
+		return nil
+	}
+
+	// LinearSolve pops a vector b {x} and a matrix A {y}
+	// and pushes the vector x which solves Ax = b.
+	LinearSolve ExprFunc = func(m *Machine) error {
+		if len(m.stack) < 2 {
+			return errUnderflow
+		}
+
+		x := m.PopX()
+		y := m.Pop()
+
+		if y.T != matrix || x.T != vector {
+			return fmt.Errorf("linsolve: invalid operands y=%#v, x=%#v", y.V, x.V)
+		}
+
+		a, b := y.V.([][]float64), x.V.([]float64)
+
+		if len(a) != len(a[0]) || len(a) != len(b) {
+			return fmt.Errorf("linsolve: mismatched sizes %dx%d, %d", len(a), len(a[0]), len(b))
+		}
+
+		r, err := solveLinear(a, b)
+
+		if err != nil {
+			return fmt.Errorf("linsolve: %w", err)
+		}
+
+		m.Push(m.makeVectorVal(r))
+		return nil
+	}
+
+	// Eigenvalues pops a symmetric matrix and pushes
+	// a vector of its eigenvalues in ascending order.
+	Eigenvalues ExprFunc = func(m *Machine) error {
+		a, err := m.popSquare("eigen")
+
+		if err != nil {
+			return err
+		}
+
+		if !symmetric(a) {
+			return fmt.Errorf("eigen: matrix not symmetric")
+		}
+
+		r, err := jacobi(a)
+
+		if err != nil {
+			return fmt.Errorf("eigen: %w", err)
+		}
+
+		m.Push(m.makeVectorVal(r))
+		return nil
+	}
+)
+
+// MatrixProduct returns an expression which multiplies
+// a matrix by a matrix or vector (in either order) using
+// the matrix product; for any other operands, the other
+// expression runs instead.
+func MatrixProduct(other Expr) ExprFunc {
+	return func(m *Machine) error {
+		l := len(m.stack)
+
+		if l < 2 {
+			return other.Eval(m)
+		}
+
+		y, x := m.stack[l-2], m.stack[l-1]
+
+		if (y.T != matrix || (x.T != matrix && x.T != vector)) &&
+			(x.T != matrix || y.T != vector) {
+			return other.Eval(m)
+		}
+
+		x = m.PopX()
+		y = m.Pop()
+
+		r, err := m.multiply(y, x)
+
+		if err != nil {
+			return err
+		}
+
+		m.Push(r)
+		return nil
+	}
+}
+
+// multiply takes the matrix product of y and x; a vector
+// is treated as a row (on the left) or column (on the right).
+func (m *Machine) multiply(y, x *Value) (Value, error) {
+	var a, b [][]float64
+
+	switch y.T {
+	case vector:
+		a = [][]float64{y.V.([]float64)}
+	default:
+		a = y.V.([][]float64)
+	}
+
+	switch x.T {
+	case vector:
+		b = transpose([][]float64{x.V.([]float64)})
+	default:
+		b = x.V.([][]float64)
+	}
+
+	if len(a) == 0 || len(a[0]) == 0 || len(b) == 0 || len(b[0]) == 0 {
+		return Value{}, fmt.Errorf("mul: empty operand")
+	}
+
+	if len(a[0]) != len(b) {
+		return Value{}, fmt.Errorf("mul: mismatched sizes %dx%d, %dx%d", len(a), len(a[0]), len(b), len(b[0]))
+	}
+
+	r := make([][]float64, len(a))
+
+	for i := range a {
+		r[i] = make([]float64, len(b[0]))
+
+		for j := range b[0] {
+			for k := range b {
+				r[i][j] += a[i][k] * b[k][j]
+			}
+		}
+	}
+
+	switch {
+	case y.T == vector:
+		return m.makeVectorVal(r[0]), nil
+	case x.T == vector:
+		return m.makeVectorVal(transpose(r)[0]), nil
+	}
+
+	return m.makeMatrixVal(r), nil
+}
+
+// popMatrix removes a matrix from the top of stack.
+func (m *Machine) popMatrix(op string) ([][]float64, error) {
+	if len(m.stack) < 1 {
+		return nil, errUnderflow
+	}
+
+	if t := m.Top(); t.T != matrix {
+		return nil, fmt.Errorf("%s: invalid operand x=%#v", op, t.V)
+	}
+
+	return m.PopX().V.([][]float64), nil
+}
+
+// popSquare removes a square matrix from the top of stack.
+func (m *Machine) popSquare(op string) ([][]float64, error) {
+	a, err := m.popMatrix(op)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(a) != len(a[0]) {
+		return nil, fmt.Errorf("%s: matrix not square %dx%d", op, len(a), len(a[0]))
+	}
+
+	return a, nil
+}
+
+// makeMatrix builds a matrix from a list of vectors
+// used as its rows, which must all be the same length.
+func makeMatrix(rows []*Value) ([][]float64, error) {
+	r := make([][]float64, len(rows))
+
+	for i, v := range rows {
+		if v.T != vector {
+			return nil, fmt.Errorf("matrix: invalid element %#v", v.V)
+		}
+
+		r[i] = v.V.([]float64)
+
+		if len(r[i]) == 0 || len(r[i]) != len(r[0]) {
+			return nil, fmt.Errorf("matrix: invalid row %d", i+1)
+		}
+	}
+
+	return r, nil
+}
+
+// binaryMatrix applies the function element by element when
+// both operands are matrices (of the same size), or else
+// broadcasts the number over each element of the matrix.
+func (m *Machine) binaryMatrix(op string, f func(float64, float64) float64, y, x *Value) (Value, error) {
+	switch {
+	case y.T == matrix && x.T == matrix:
+		a, b := y.V.([][]float64), x.V.([][]float64)
+
+		if len(a) != len(b) || len(a[0]) != len(b[0]) {
+			return Value{}, fmt.Errorf("%s: mismatched sizes %dx%d, %dx%d", op, len(a), len(a[0]), len(b), len(b[0]))
+		}
+
+		return m.makeMatrixVal(mapMatrix(a, func(i, j int, e float64) float64 { return f(e, b[i][j]) })), nil
+
+	case y.T == matrix:
+		if b, ok := x.float(); ok {
+			a := y.V.([][]float64)
+			return m.makeMatrixVal(mapMatrix(a, func(_, _ int, e float64) float64 { return f(e, b) })), nil
+		}
+
+	case x.T == matrix:
+		if a, ok := y.float(); ok {
+			b := x.V.([][]float64)
+			return m.makeMatrixVal(mapMatrix(b, func(_, _ int, e float64) float64 { return f(a, e) })), nil
+		}
+	}
+
+	return Value{}, fmt.Errorf("%s: mismatched operands y=%#v, x=%#v", op, y.V, x.V)
+}
+
+// unaryMatrix applies the function to each element.
+func (m *Machine) unaryMatrix(f func(float64) float64, x *Value) Value {
+	a := x.V.([][]float64)
+	return m.makeMatrixVal(mapMatrix(a, func(_, _ int, e float64) float64 { return f(e) }))
+}
+
+// mapMatrix returns a new matrix built by calling
+// the function on each element of the original.
+func mapMatrix(a [][]float64, f func(int, int, float64) float64) [][]float64 {
+	r := make([][]float64, len(a))
+
+	for i := range a {
+		r[i] = make([]float64, len(a[i]))
+
+		for j := range a[i] {
+			r[i][j] = f(i, j, a[i][j])
+		}
+	}
+
+	return r
+}
+
+// clone returns a copy of a matrix so that it can be
+// modified without changing a value on the stack.
+func clone(a [][]float64) [][]float64 {
+	return mapMatrix(a, func(_, _ int, e float64) float64 { return e })
+}
+
+func transpose(a [][]float64) [][]float64 {
+	r := make([][]float64, len(a[0]))
+
+	for j := range r {
+		r[j] = make([]float64, len(a))
+
+		for i := range a {
+			r[j][i] = a[i][j]
+		}
+	}
+
+	return r
+}
+
+func symmetric(a [][]float64) bool {
+	for i := range a {
+		for j := 0; j < i; j++ {
+			if a[i][j] != a[j][i] {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// eliminate reduces the matrix to upper-triangular form using
+// Gaussian elimination with partial pivoting, applying the same
+// row operations to b (which may be nil); it returns the sign
+// of the row permutation, or zero if the matrix is singular.
+func eliminate(a [][]float64, b [][]float64) float64 {
+	s := 1.0
+	n := len(a)
+
+	for k := 0; k < n; k++ {
+		p := k
+
+		for i := k + 1; i < n; i++ {
+			if math.Abs(a[i][k]) > math.Abs(a[p][k]) {
+				p = i
+			}
+		}
+
+		if a[p][k] == 0 {
+			return 0
+		}
+
+		if p != k {
+			a[p], a[k] = a[k], a[p]
+
+			if b != nil {
+				b[p], b[k] = b[k], b[p]
+			}
+
+			s = -s
+		}
+
+		for i := k + 1; i < n; i++ {
+			f := a[i][k] / a[k][k]
+
+			for j := k; j < n; j++ {
+				a[i][j] -= f * a[k][j]
+			}
+
+			if b != nil {
+				for j := range b[i] {
+					b[i][j] -= f * b[k][j]
+				}
+			}
+		}
+	}
+
+	return s
+}
+
+// substitute solves the upper-triangular system left by
+// eliminate, for each column of b, in place.
+func substitute(a [][]float64, b [][]float64) {
+	n := len(a)
+
+	for c := range b[0] {
+		for i := n - 1; i >= 0; i-- {
+			s := b[i][c]
+
+			for j := i + 1; j < n; j++ {
+				s -= a[i][j] * b[j][c]
+			}
+
+			b[i][c] = s / a[i][i]
+		}
+	}
+}
+
+func determinant(a [][]float64) float64 {
+	u := clone(a)
+	d := eliminate(u, nil)
+
+	for i := range u {
+		d *= u[i][i]
+	}
+
+	return d
+}
+
+func inverse(a [][]float64) ([][]float64, error) {
+	u := clone(a)
+	b := mapMatrix(a, func(i, j int, _ float64) float64 {
+		if i == j {
+			return 1
+		}
+
+		return 0
+	})
+
+	if eliminate(u, b) == 0 {
+		return nil, errSingular
+	}
+
+	substitute(u, b)
+	return b, nil
+}
+
+func solveLinear(a [][]float64, v []float64) ([]float64, error) {
+	u := clone(a)
+	b := transpose([][]float64{v})
+
+	if eliminate(u, b) == 0 {
+		return nil, errSingular
+	}
+
+	substitute(u, b)
+	return transpose(b)[0], nil
+}
+
+// jacobi finds the eigenvalues of a symmetric matrix by the
+// cyclic Jacobi method, rotating away the off-diagonal elements
+// until they're negligible.
+func jacobi(a [][]float64) ([]float64, error) {
+	u := clone(a)
+	n := len(u)
+
+	for sweep := 0; sweep < maxSweeps; sweep++ {
+		var off, all float64
+
+		for i := range u {
+			for j := range u[i] {
+				if i != j {
+					off += u[i][j] * u[i][j]
+				}
+
+				all += u[i][j] * u[i][j]
+			}
+		}
+
+		if off <= 1e-30*all {
+			r := make([]float64, n)
+
+			for i := range r {
+				r[i] = u[i][i]
+			}
+
+			sort.Float64s(r)
+			return r, nil
+		}
+
+		for p := 0; p < n-1; p++ {
+			for q := p + 1; q < n; q++ {
+				if u[p][q] == 0 {
+					continue
+				}
+
+				// choose the rotation which zeroes u[p][q]
+
+				theta := (u[q][q] - u[p][p]) / (2 * u[p][q])
+				t := math.Copysign(1, theta) / (math.Abs(theta) + math.Sqrt(theta*theta+1))
+				c := 1 / math.Sqrt(t*t+1)
+				s := t * c
+
+				for k := 0; k < n; k++ {
+					kp, kq := u[k][p], u[k][q]
+					u[k][p] = c*kp - s*kq
+					u[k][q] = s*kp + c*kq
+				}
+
+				for k := 0; k < n; k++ {
+					pk, qk := u[p][k], u[q][k]
+					u[p][k] = c*pk - s*qk
+					u[q][k] = s*pk + c*qk
+				}
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("no convergence")
+}
+
+// formatMatrix shows a matrix one row per line, with
+// the columns lined up in the current display mode.
+func (m *Machine) formatMatrix(a [][]float64) string {
+	s := make([][]string, len(a))
+	w := make([]int, len(a[0]))
+
+	for i := range a {
+		s[i] = make([]string, len(a[i]))
+
+		for j, f := range a[i] {
+			s[i][j] = m.formatFloat(f)
+
+			if l := len(s[i][j]); l > w[j] {
+				w[j] = l
+			}
+		}
+	}
+
+	rows := make([]string, len(a))
+
+	for i := range s {
+		for j := range s[i] {
+			s[i][j] = fmt.Sprintf("%*s", w[j], s[i][j])
+		}
+
+		rows[i] = "[" + strings.Join(s[i], " ") + "]"
+	}
+
+	return "[" + strings.Join(rows, "\n ") + "]"
+}
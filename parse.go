@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math/big"
 	"regexp"
 	"strconv"
 	"strings"
@@ -21,7 +22,9 @@ type Parser struct {
 	buff    [100]token.Token
 	w       io.Writer
 	word    *Word
+	self    *Word
 	scope   *Scope
+	blocks  []*block
 	line    int
 	base    int
 	debug   bool
@@ -73,6 +76,7 @@ func WordParser(m *Machine, t []token.Token, s *Scope) *Parser {
 // This requires the scanner to return tokens for newline or comma.
 func (p *Parser) Line() ([]Expr, string, error) {
 	p.base = p.machine.Base()
+	p.scanner.setBase(p.base)
 
 	s, ok := p.readTokensToNewline()
 
@@ -92,6 +96,24 @@ func (p *Parser) Line() ([]Expr, string, error) {
 	return e, s, err
 }
 
+// SetScanner replaces the parser's scanner, so that a word
+// definition may continue from one line of input to the next
+// (e.g., when each line is read separately by readline).
+func (p *Parser) SetScanner(s *Scanner) {
+	p.scanner = s
+}
+
+// Defining returns true if the parser is in the middle
+// of a word definition that hasn't yet been completed.
+func (p *Parser) Defining() bool {
+	return p.word != nil
+}
+
+// Abandon throws away any incomplete word definition.
+func (p *Parser) Abandon() {
+	p.word = nil
+}
+
 // Compile is only used for a WordParser, to compile
 // the word's tokens once they've all been picked up.
 func (p *Parser) Compile() ([]Expr, error) {
@@ -99,7 +121,20 @@ func (p *Parser) Compile() ([]Expr, error) {
 		fmt.Printf("%d: %s\n", p.line, p.tokens)
 	}
 
-	return p.evaluate()
+	e, err := p.evaluate()
+
+	if err != nil {
+		return nil, err
+	}
+
+	// every control structure must be closed
+	// before the end of the word's definition
+
+	if b := p.block(); b != nil {
+		return nil, fmt.Errorf("unterminated %s", b.kind)
+	}
+
+	return e, nil
 }
 
 // readTokensToNewline clears the token buffer and fills it
@@ -140,7 +175,9 @@ func (p *Parser) evaluate() ([]Expr, error) { // nolint:gocyclo
 		err    error
 	)
 
-	for _, t := range p.tokens {
+	for i := 0; i < len(p.tokens); i++ {
+		t := p.tokens[i]
+
 		// if we're picking up a word definition we just
 		// collect the tokens and interpret them later,
 		// so we avoid the main switch statement
@@ -160,6 +197,7 @@ func (p *Parser) evaluate() ([]Expr, error) { // nolint:gocyclo
 			if t.Type == token.Semicolon {
 				if err := p.word.Compile(p.machine); err != nil {
 					p.errorf("invalid word: %s", err)
+					p.word = nil
 					return nil, err
 				}
 
@@ -172,6 +210,15 @@ func (p *Parser) evaluate() ([]Expr, error) { // nolint:gocyclo
 
 		switch t.Type {
 		case token.Number:
+			// what looks like a unit after a number may be
+			// a word (e.g., 1 g), so we make it separate
+
+			if n, u := splitUnit(t.Text); u != "" && p.isName(u) {
+				w := token.Token{Type: token.Identifier, Line: t.Line, Text: u}
+				p.tokens = append(p.tokens[:i+1], append([]token.Token{w}, p.tokens[i+1:]...)...)
+				t.Text = n
+			}
+
 			if e, err = p.number(t.Text); err != nil {
 				p.errorf("%s: %s", err, t.Text)
 				return nil, err
@@ -183,6 +230,12 @@ func (p *Parser) evaluate() ([]Expr, error) { // nolint:gocyclo
 				return nil, err
 			}
 
+		case token.LeftBracket:
+			e = StartVector
+
+		case token.RightBracket:
+			e = EndVector
+
 		case token.LeftParen:
 			// we should only have () lists inside a
 			// word definition, which has a scope
@@ -198,7 +251,12 @@ func (p *Parser) evaluate() ([]Expr, error) { // nolint:gocyclo
 			e = nil // avoid duplicating last def!
 
 		case token.Identifier:
-			if p.scoped {
+			if isControl(t.Text) {
+				if e, err = p.control(t.Text); err != nil {
+					p.errorf("%s", err)
+					return nil, err
+				}
+			} else if p.scoped {
 				if e, err = p.scope.Add(t.Text); err != nil {
 					p.errorf("bad local: %s", t.Text)
 					return nil, err
@@ -217,7 +275,7 @@ func (p *Parser) evaluate() ([]Expr, error) { // nolint:gocyclo
 				// we need to allow binary input in the middle
 				// of an input line when there's a mode change
 
-				p.checkForBaseChange(t.Text)
+				p.checkForBaseChange(i, t.Text)
 			}
 
 		case token.String:
@@ -237,7 +295,14 @@ func (p *Parser) evaluate() ([]Expr, error) { // nolint:gocyclo
 		}
 
 		if e != nil {
-			result = append(result, e)
+			// inside a control structure, the expression
+			// belongs to the block rather than the result
+
+			if b := p.block(); b != nil {
+				b.add(e)
+			} else {
+				result = append(result, e)
+			}
 		}
 	}
 
@@ -257,32 +322,84 @@ func (p *Parser) errorf(format string, args ...interface{}) {
 	fmt.Fprintf(p.w, format+"\n", args...)
 }
 
-func (p *Parser) number(s string) (result Expr, err error) {
-	if p.base != 10 {
-		// if we're in integer mode, we want to parse integers, possibly
-		// with a leading 0 or 0x/0b prefix; floats will not work here
+func (p *Parser) number(s string) (Expr, error) {
+	return parseNumber(s, p.base)
+}
+
+// parseNumber reads a number in the given base, where any base
+// other than 10 means the number must be an unsigned integer;
+// a number is kept exactly in case of big-integer or exact mode.
+func parseNumber(s string, base int) (Expr, error) {
+	if dateLiteral.MatchString(s) {
+		t, err := parseDate(s)
 
-		var n uint64
+		if err != nil {
+			return nil, err
+		}
 
-		if len(s) > 0 && s[0] == '0' {
-			if len(s) > 2 {
-				// if we don't have a prefix, we have a leading 0 for octal;
-				// however, we aren't going to accept (e.g.) "0x" by itself
+		return DateLiteral(t), nil
+	}
 
-				if s[1] == 'x' || s[1] == 'X' {
-					n, err = strconv.ParseUint(s[2:], 16, 64)
-				} else if s[1] == 'b' || s[1] == 'B' {
-					n, err = strconv.ParseUint(s[2:], 2, 64)
-				} else {
-					n, err = strconv.ParseUint(s, 8, 64)
-				}
-			} else {
-				n, err = strconv.ParseUint(s, 10, 64)
-			}
-		} else {
-			n, err = strconv.ParseUint(s, 10, 64)
+	if n, u := splitUnit(s); u != "" {
+		r, err := parseUnit(u)
+
+		if err != nil {
+			return nil, err
+		}
+
+		e, err := parseNumber(n, base)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return UnitLiteral(r, e), nil
+	}
+
+	if strings.Contains(s, "°") {
+		f, err := parseDMS(s)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return DegreesLiteral(f), nil
+	}
+
+	e, err := parseFixed(s, base)
+
+	if base == 10 && err == nil {
+		if r, ok := new(big.Rat).SetString(s); ok {
+			e = FloatLiteral(s, RatLiteral(r, e))
+		}
+	}
+
+	if n, ok := parseBig(s, base); ok {
+		if err != nil {
+			err = fmt.Errorf("%s: %s", err, s)
+		}
+
+		return BigLiteral(n, e, err), nil
+	}
+
+	return e, err
+}
+
+// parseFixed reads a number as a float or (in a base other
+// than 10) an unsigned integer of the usual size.
+func parseFixed(s string, base int) (result Expr, err error) {
+	if base != 10 {
+		// if we're in integer mode, we want to parse integers, possibly
+		// with a leading 0 or 0x/0b/0r prefix; floats will not work here
+
+		d, b, err := splitRadix(s, base)
+
+		if err != nil {
+			return nil, err
 		}
 
+		n, err := strconv.ParseUint(d, b, 64)
+
 		if err != nil {
 			return nil, err
 		}
@@ -290,6 +407,24 @@ func (p *Parser) number(s string) (result Expr, err error) {
 		return Integer(uint(n)), nil
 	}
 
+	// a number in any base (e.g., 0r36:zz) is still a float
+
+	if isRadix(s) {
+		d, b, err := splitRadix(s, base)
+
+		if err != nil {
+			return nil, err
+		}
+
+		n, err := strconv.ParseUint(d, b, 64)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return Number(float64(n)), nil
+	}
+
 	if strings.HasPrefix(s, "0b") || strings.HasPrefix(s, "0B") ||
 		strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
 		return nil, fmt.Errorf("binary format invalid")
@@ -304,16 +439,78 @@ func (p *Parser) number(s string) (result Expr, err error) {
 	return Number(f), nil
 }
 
-func (p *Parser) checkForBaseChange(id string) {
-	switch id {
-	case "bin":
-		p.base = 2
-	case "dec":
-		p.base = 10
-	case "oct":
-		p.base = 8
-	case "hex":
-		p.base = 16
+// isRadix reports whether a number has a prefix
+// giving its base (e.g., 0r36:zz).
+func isRadix(s string) bool {
+	return strings.HasPrefix(s, "0r") || strings.HasPrefix(s, "0R")
+}
+
+// splitRadix returns the digits of an integer and their base:
+// a prefix 0x, 0b, or 0rN: (for any base N from 2 to 36), or a
+// leading 0 for octal, sets the base; otherwise, the number is
+// decimal unless it has digits only valid in the input base.
+func splitRadix(s string, base int) (string, int, error) {
+	// we aren't going to accept (e.g.) "0x" by itself
+
+	if len(s) > 2 && s[0] == '0' {
+		switch s[1] {
+		case 'x', 'X':
+			return s[2:], 16, nil
+		case 'b', 'B':
+			return s[2:], 2, nil
+		case 'r', 'R':
+			i := strings.IndexByte(s, ':')
+
+			if i < 0 {
+				return "", 0, fmt.Errorf("missing base")
+			}
+
+			b, err := strconv.Atoi(s[2:i])
+
+			if err != nil || b < 2 || b > 36 {
+				return "", 0, fmt.Errorf("invalid base")
+			}
+
+			return s[i+1:], b, nil
+		}
+
+		return s, 8, nil
+	}
+
+	if strings.IndexFunc(s, func(r rune) bool { return r < '0' || r > '9' }) >= 0 {
+		return s, base, nil
+	}
+
+	return s, 10, nil
+}
+
+// isName reports whether an identifier is the name of
+// a word or an operation, rather than a unit.
+func (p *Parser) isName(s string) bool {
+	if Predefined(s) != nil || isControl(s) || p.machine.Word(s) != nil {
+		return true
+	}
+
+	if p.self != nil && p.self.N == s {
+		return true
+	}
+
+	_, err := p.machine.Builtin(s)
+	return err == nil
+}
+
+// checkForBaseChange follows a change of input base by the
+// identifier at position i, just as the scanner did.
+func (p *Parser) checkForBaseChange(i int, id string) {
+	var prev string
+
+	if i > 0 && p.tokens[i-1].Type == token.Number {
+		prev = p.tokens[i-1].Text
+	}
+
+	if base, ok := baseChange(prev, id); ok {
+		p.base = base
+		p.scanner.setBase(base)
 	}
 }
 
@@ -343,6 +540,8 @@ func (p *Parser) operator(s string) (Expr, error) {
 		return Store, nil
 	case "@":
 		return Recall, nil
+	case ".":
+		return Print, nil
 	case "~":
 		return Not, nil
 	case "&":
@@ -357,6 +556,18 @@ func (p *Parser) operator(s string) (Expr, error) {
 		return RightShift, nil
 	case ">>>":
 		return ArithShift, nil
+	case "==":
+		return Equal, nil
+	case "!=":
+		return NotEqual, nil
+	case "<":
+		return Less, nil
+	case "<=":
+		return LessEqual, nil
+	case ">":
+		return Greater, nil
+	case ">=":
+		return GreaterEqual, nil
 	case "∑+":
 		return StatsOpAdd, nil
 	case "∑-":
@@ -387,6 +598,10 @@ func (p *Parser) symbol(s string) (Expr, error) {
 	// if it's a word preceded by $, add it as a
 	// symbol so it's not executed yet
 
+	if p.self != nil && p.self.N == s[1:] {
+		return WordRef(p.self), nil
+	}
+
 	if w := p.machine.Word(s[1:]); w != nil {
 		return WordRef(w.(*Word)), nil
 	}
@@ -414,6 +629,11 @@ func (p *Parser) identifier(s string) (Expr, error) {
 
 	// a word without a preceding $ is treated
 	// as a predefined/built-in op: call it now
+	// (including the word we're compiling)
+
+	if p.self != nil && p.self.N == s {
+		return p.self, nil
+	}
 
 	if w := p.machine.Word(s); w != nil {
 		return w, nil
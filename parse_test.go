@@ -190,6 +190,16 @@ var parseTests = []parseTest{
 		input: `2 fix 4.63 0 sum, 5.78 20 sum, 6.61 40 sum, 7.21 60 sum, 7.78 80 sum, mean, swap`,
 		want:  []string{"1.00", "2.00", "3.00", "4.00", "5.00", "40.00", "6.40"},
 	},
+	{
+		name:  "stats-hex",
+		input: `hex 1 ∑+ drop 3 ∑+ drop 5 ∑+, mean`,
+		want:  []string{"3", "0x0003"},
+	},
+	{
+		name:  "stats-bin",
+		input: `1 bin sum, mean`,
+		want:  []string{"1", "0b00000001"},
+	},
 	{
 		name:  "stats-one-var",
 		input: `2 fix 4.63 sum, 5.78 sum, 6.61 sum, 7.21 sum, 7.78 sum, mean`,
@@ -200,6 +210,46 @@ var parseTests = []parseTest{
 		input: `2 fix 4.63 0 ∑+, 5.78 20 ∑+, 6.61 40 ∑+, 7.21 60 ∑+, 7.78 80 ∑+, stdev, swap`,
 		want:  []string{"1.00", "2.00", "3.00", "4.00", "5.00", "31.62", "1.24"},
 	},
+	{
+		name:  "stats-ttest",
+		input: `2 fix 4.63 sum, 5.78 sum, 6.61 sum, 7.21 sum, 7.78 sum, 0.95 ttest, swap`,
+		want:  []string{"1.00", "2.00", "3.00", "4.00", "5.00", "7.94", "4.87"},
+	},
+	{
+		name:  "stats-ttest-invalid",
+		input: `4.63 sum 5.78 sum 95 ttest`,
+		fail:  "ttest: invalid confidence level 95",
+	},
+	{
+		name:  "stats-median",
+		input: `2 fix 7 sum, 1 sum, 4 sum, 9 sum, 3 sum, median, 0.25 quantile, iqr, sort`,
+		want:  []string{"1.00", "2.00", "3.00", "4.00", "5.00", "4.00", "3.00", "4.00", "[1.00 3.00 4.00 7.00 9.00]"},
+	},
+	{
+		name:  "stats-median-xy",
+		input: `2 fix 4.63 0 sum, 5.78 20 sum, 6.61 40 sum, 7.21 60 sum, median, swap, 7.21 60 ∑-, median, swap`,
+		want:  []string{"1.00", "2.00", "3.00", "4.00", "30.00", "6.20", "3.00", "20.00", "5.78"},
+	},
+	{
+		name:  "stats-quantile-invalid",
+		input: `1 sum 2 sum 1.5 quantile`,
+		fail:  "quantile: invalid probability 1.5",
+	},
+	{
+		name:  "stats-quantile-nan",
+		input: `1 sum 0 0 / quantile`,
+		fail:  "quantile: invalid probability NaN",
+	},
+	{
+		name:  "stats-median-list",
+		input: `2 fix [7 1 4 9 3] median, [1 2 3 4] 0.25 quantile, [1 2 3 4 5] iqr, [3 1 2] sort, depth`,
+		want:  []string{"4.00", "1.75", "2.00", "[1.00 2.00 3.00]", "4.00"},
+	},
+	{
+		name:  "stats-median-empty",
+		input: `median`,
+		fail:  "stats empty",
+	},
 	{
 		name:  "stats-sterr",
 		input: `3 fix 99.794 ∑+, 99.805 ∑+, 99.198 ∑+, 99.829 ∑+, 99.541 ∑+, mean, sterr`,
@@ -270,6 +320,710 @@ var parseTests = []parseTest{
 		input: `4 fix :f recp; 2 $f d2dx`,
 		want:  []string{"0.2500"},
 	},
+	{
+		name:  "if-else-then",
+		input: `:f if 10 else 20 then; 1 f, 0 f`,
+		want:  []string{"10", "20"},
+	},
+	{
+		name:  "if-then",
+		input: `:f if 2* then; 3 1 f, 0 f`,
+		want:  []string{"6", "6"},
+	},
+	{
+		name:  "if-nested",
+		input: `:g if if 1 else 2 then else 3 then; 1 1 g, 0 1 g, 0 g`,
+		want:  []string{"1", "2", "3"},
+	},
+	{
+		name:  "if-unterminated",
+		input: `:f if 1;`,
+		err:   "unterminated if",
+	},
+	{
+		name:  "then-without-if",
+		input: `:f 1 then;`,
+		err:   "then without if",
+	},
+	{
+		name:  "if-outside-word",
+		input: `1 if 2 then`,
+		err:   "if: only valid in a word",
+	},
+	{
+		name:  "do-loop",
+		input: `:s 0 10 0 do i + loop; s`,
+		want:  []string{"45"},
+	},
+	{
+		name:  "do-loop-nested",
+		input: `:t 0 3 0 do 2 0 do j 10* i + + loop loop; t`,
+		want:  []string{"63"},
+	},
+	{
+		name:  "do-loop-empty",
+		input: `:s 7 0 0 do i + loop; s`,
+		want:  []string{"7"},
+	},
+	{
+		name:  "begin-until",
+		input: `:u begin 1+ dup 3 % until; 2 u, 0 u`,
+		want:  []string{"4", "1"},
+	},
+	{
+		name:  "begin-while",
+		input: `:w begin dup while 1- repeat; 5 w`,
+		want:  []string{"0"},
+	},
+	{
+		name:  "loop-limit",
+		input: `10 maxloop :r begin 0 until; r`,
+		fail:  "loop limit exceeded",
+	},
+	{
+		name:  "loop-limit-nested",
+		input: `10 maxloop :n 0 4 0 do 4 0 do 1+ loop loop; n`,
+		fail:  "loop limit exceeded",
+	},
+	{
+		name:  "loop-limit-per-line",
+		input: `20 maxloop :n 0 4 0 do 4 0 do 1+ loop loop; n, n`,
+		want:  []string{"16", "16"},
+	},
+	{
+		name:  "loop-limit-negative",
+		input: `-1 maxloop`,
+		fail:  "maxloop: invalid operand x=-1",
+	},
+	{
+		name:  "loop-limit-huge",
+		input: `1e19 maxloop`,
+		fail:  "maxloop: invalid operand x=1e+19",
+	},
+	{
+		name:  "loop-limit-string",
+		input: `"x" maxloop`,
+		fail:  `maxloop: invalid operand x="x"`,
+	},
+	{
+		name:  "index-outside-loop",
+		input: `:f i;`,
+		err:   "i outside of do loop",
+	},
+	{
+		name:  "loop-without-do",
+		input: `:f begin loop;`,
+		err:   "loop without do",
+	},
+	{
+		name:  "compare",
+		input: `2 3 <, 3 3 <=, 3 2 >, 2 3 >=, 2 2 ==, 2 2 !=`,
+		want:  []string{"1", "1", "1", "0", "1", "0"},
+	},
+	{
+		name:  "compare-strings",
+		input: `"abc" "abd" <, "abc" "abc" ==`,
+		want:  []string{"1", "1"},
+	},
+	{
+		name:  "compare-hex",
+		input: `hex 0xff 0x100 <`,
+		want:  []string{"0x0001"},
+	},
+	{
+		name:  "compare-nan",
+		input: `0 0 / 1 ==, 0 0 / 1 !=, 0 0 / 1 <, 0 0 / 1 <=, 0 0 / 1 >, 0 0 / 1 >=, 0 0 / dup ==`,
+		want:  []string{"0", "1", "0", "0", "0", "0", "0"},
+	},
+	{
+		name:  "compare-complex",
+		input: `1 2 cplx 1 2 cplx ==, 1 2 cplx 1 -2 cplx ==, 1 2 cplx 1 -2 cplx !=, 3 0 cplx 3 ==`,
+		want:  []string{"1", "0", "1", "1"},
+	},
+	{
+		name:  "compare-complex-order",
+		input: `1 2 cplx 1 2 cplx <`,
+		fail:  `lt: mismatched operands y=(1+2i), x=(1+2i)`,
+	},
+	{
+		name:  "compare-mismatch",
+		input: `"abc" 1 <`,
+		fail:  `lt: mismatched operands y="abc", x=1`,
+	},
+	{
+		name:  "logic",
+		input: `1 0 and, 1 0 or, not, 1 2 < 3 2 > and`,
+		want:  []string{"0", "1", "0", "1"},
+	},
+	{
+		name:  "if-compare",
+		input: `:sgn dup 0 < if drop -1 else 0 > then; -4 sgn, 3 sgn, 0 sgn`,
+		want:  []string{"-1", "1", "0"},
+	},
+	{
+		name:  "recursive-factorial",
+		input: `:fac dup 1 <= if drop 1 else dup 1- fac * then; 5 fac`,
+		want:  []string{"120"},
+	},
+	{
+		name:  "recursive-gcd",
+		input: `:gcd dup 0 == if drop else swap over % gcd then; 12 18 gcd`,
+		want:  []string{"6"},
+	},
+	{
+		name:  "recursive-countdown",
+		input: `:cd dup 0 > if 1- cd then; 5 cd`,
+		want:  []string{"0"},
+	},
+	{
+		name:  "recursion-too-deep",
+		input: `:inf inf; inf`,
+		fail:  "recursion too deep",
+	},
+	{
+		name:  "vector",
+		input: `[1 2 3], [1 2 3] [4 5 6] +, 2 *, 1 swap -`,
+		want:  []string{"[1 2 3]", "[5 7 9]", "[10 14 18]", "[-9 -13 -17]"},
+	},
+	{
+		name:  "vector-expr",
+		input: `2 fix [90 180] sin`,
+		want:  []string{"[1.00 0.00]"},
+	},
+	{
+		name:  "vector-reductions",
+		input: `[1 2 3 4] sum, [1 2 3 4] prod, [3 4] norm, [3 1 2] min, [3 1 2] max, [1 2 3] [4 5 6] dot`,
+		want:  []string{"10", "24", "5", "1", "3", "32"},
+	},
+	{
+		name:  "vector-chs",
+		input: `[1 -2] chs`,
+		want:  []string{"[-1 2]"},
+	},
+	{
+		name:  "vector-store",
+		input: `[1 2] $v ! 0, $v @ 3 *`,
+		want:  []string{"0", "[3 6]"},
+	},
+	{
+		name:  "vector-mismatch",
+		input: `[1 2] [1 2 3] +`,
+		fail:  "add: mismatched lengths 2, 3",
+	},
+	{
+		name:  "vector-unmatched",
+		input: `1 2]`,
+		fail:  "unmatched ]",
+	},
+	{
+		name:  "matrix",
+		input: `[[1 2] [3 4]], trn, 2 *, [[1 2] [3 4]] [[1 0] [0 1]] +`,
+		want:  []string{"[[1 2]\n [3 4]]", "[[1 3]\n [2 4]]", "[[2 6]\n [4 8]]", "[[2 2]\n [3 5]]"},
+	},
+	{
+		name:  "matrix-product",
+		input: `[[1 2] [3 4]] dup *, [[1 2] [3 4]] [1 1] *, [1 1] [[1 2] [3 4]] *`,
+		want:  []string{"[[ 7 10]\n [15 22]]", "[3 7]", "[4 6]"},
+	},
+	{
+		name:  "matrix-algebra",
+		input: `1 fix [[1 2] [3 4]] det, [[1 2] [3 4]] inv, [[2 1] [1 3]] [3 5] linsolve, [[2 1] [1 2]] eigen`,
+		want:  []string{"-2.0", "[[-2.0  1.0]\n [ 1.5 -0.5]]", "[0.8 1.4]", "[1.0 3.0]"},
+	},
+	{
+		name:  "matrix-ragged",
+		input: `[[1 2] [3]]`,
+		fail:  "matrix: invalid row 2",
+	},
+	{
+		name:  "matrix-empty",
+		input: `[[1 2] [3 4]] [] *`,
+		fail:  "mul: empty operand",
+	},
+	{
+		name:  "matrix-empty-left",
+		input: `[] [[1 2] [3 4]] *`,
+		fail:  "mul: empty operand",
+	},
+	{
+		name:  "matrix-singular",
+		input: `[[1 2] [2 4]] inv`,
+		fail:  "inv: singular matrix",
+	},
+	{
+		name:  "matrix-not-square",
+		input: `[[1 2 3] [4 5 6]] det`,
+		fail:  "det: matrix not square 2x3",
+	},
+	{
+		name:  "complex",
+		input: `1 2 cplx, 3 4 cplx *, abs, 3 -4 cplx conj, re, 1 2 cplx im`,
+		want:  []string{"(1+2i)", "(-5+10i)", "11.180339887498949", "(3+4i)", "3", "2"},
+	},
+	{
+		name:  "complex-display",
+		input: `1 2 cplx 2 fix, 1 sci, 1000 -2000 cplx 1 eng`,
+		want:  []string{"(1.00+2.00i)", "(1.0e+00+2.0e+00i)", "(1.0e+03-2.0e+03i)"},
+	},
+	{
+		name:  "complex-polar",
+		input: `2 fix 1 1 cplx polar, 2 45 rect, "rad" mode 0 1 cplx arg`,
+		want:  []string{"45.00", "(1.41+1.41i)", "1.57"},
+	},
+	{
+		name:  "complex-mode",
+		input: `2 fix -1 sqrt, "complex" mode -1 sqrt, -4 sqrt 2 + sqr, -1 ln, "real" mode -1 sqrt`,
+		want:  []string{"NaN", "(0.00+1.00i)", "(0.00+8.00i)", "(0.00+3.14i)", "NaN"},
+	},
+	{
+		name:  "complex-trig",
+		input: `2 fix "complex" mode 0.5 asin, 2 asin, 2 acos, 90 0 cplx sin, "rad" mode 2 asin, 0 1 cplx sin`,
+		want:  []string{"30.00", "(90.00+75.46i)", "(0.00-75.46i)", "(1.00+0.00i)", "(1.57+1.32i)", "(0.00+1.18i)"},
+	},
+	{
+		name:  "complex-invalid",
+		input: `1 2 cplx floor`,
+		fail:  "floor: invalid complex operand x=(1+2i)",
+	},
+	{
+		name:  "big-exact",
+		input: `"big" mode 2 100 **, 30 fact, 12345678901234567890 1 +, 7 2 /, 8 2 /, 10 3 comb`,
+		want:  []string{"1267650600228229401496703205376", "265252859812191058636308480000000", "12345678901234567891", "3.5", "4", "120"},
+	},
+	{
+		name:  "big-bitwise",
+		input: `"big" mode hex 0xffffffffffffffffff 1 +, 4 <<, 0xff00 0x0ff0 &, 0xf0 ^, 8 >>, dec 2 64 ** 1 - popcnt`,
+		want:  []string{"0x1000000000000000000", "0x10000000000000000000", "0x0f00", "0x0ff0", "0x000f", "64"},
+	},
+	{
+		name:  "big-compare",
+		input: `"big" mode 12345678901234567890 12345678901234567891 <, 3 3.0 ==, 2 100 ** chs abs`,
+		want:  []string{"1", "1", "1267650600228229401496703205376"},
+	},
+	{
+		name:  "big-vector",
+		input: `"big" mode [1 2] 3 *, 2 [1 2] -`,
+		want:  []string{"[3 6]", "[1 0]"},
+	},
+	{
+		name:  "big-pow-huge",
+		input: `"big" mode 2 1000000000 **`,
+		fail:  "pow: result too large",
+	},
+	{
+		name:  "exact-vector",
+		input: `"exact" mode [1 2] 2 /, 1 3 / [3 6] *`,
+		want:  []string{"[0.5 1]", "[1 2]"},
+	},
+	{
+		name:  "exact-pow-huge",
+		input: `"exact" mode 1 2 / 1000000000 **`,
+		fail:  "pow: result too large",
+	},
+	{
+		name:  "precise-vector",
+		input: `"precise" mode 2 [1 2] +, [1 2] 0.5 **`,
+		want:  []string{"[3 4]", "[1 1.4142135623730951]"},
+	},
+	{
+		name:  "precise-pow-huge",
+		input: `"precise" mode 2 1000000000 **`,
+		fail:  "pow: result too large",
+	},
+	{
+		name:  "big-off",
+		input: `171 fact, hex 0xff 1 +`,
+		want:  []string{"+Inf", "0x0100"},
+	},
+	{
+		name:  "big-overflow",
+		input: `hex 0xffffffffffffffffff`,
+		fail:  `strconv.ParseUint: parsing "ffffffffffffffffff": value out of range: 0xffffffffffffffffff`,
+	},
+	{
+		name:  "radix",
+		input: `36 base 35, 0r36:zz 1 +, 1zz, 0x10, 3 base 0r3:21, 7, dec, hex 1f`,
+		want:  []string{"0r36:z", "0r36:100", "0r36:1zz", "0r36:g", "0r3:21", "0r3:21", "7", "0x001f"},
+	},
+	{
+		name:  "radix-big",
+		input: `"big" mode 36 base 35, 0r36:zzzzzzzzzzzzzzz 1 +, -5`,
+		want:  []string{"0r36:z", "0r36:1000000000000000", "-0r36:5"},
+	},
+	{
+		name:  "radix-same-line",
+		input: `36 base 1zz, 16 base 1f, 36 base 0r36:zz, 3 base 0r36:zz`,
+		want:  []string{"0r36:1zz", "0x001f", "0r36:zz", "0r3:1202222"},
+	},
+	{
+		name:  "radix-decimal",
+		input: `0r36:zz, 0r2:101 1 +, "big" mode 0r36:zzzzzzzzzzzzzzz`,
+		want:  []string{"1295", "6", "221073919720733357899775"},
+	},
+	{
+		name:  "radix-invalid",
+		input: `0x10`,
+		err:   "binary format invalid",
+	},
+	{
+		name:  "word-unsigned",
+		input: `hex 8 ws 0xff 1 +, carry, ovfl, 0x1ff, 0x81 1 <<, carry, bin 4 ws 0b0101 ~`,
+		want:  []string{"0x00", "0x01", "0x01", "0xff", "0x02", "0x01", "0b1010"},
+	},
+	{
+		name:  "word-2s",
+		input: `hex 8 ws "2s" mode 0x7f 1 +, carry, ovfl, 5 chs, 0x81 1 >>>, 0xff 1 <, 0x80 dec, status`,
+		want:  []string{"0x80", "0x00", "0x01", "0xfb", "0xc0", "0x01", "-128", "-128"},
+	},
+	{
+		name:  "word-1s",
+		input: `hex 8 ws "1s" mode 5 chs, 0xfe dec, hex 0xfe 3 +, 7 2 /, carry`,
+		want:  []string{"0xfa", "-1", "0x02", "0x03", "0x01"},
+	},
+	{
+		name:  "word-rotate",
+		input: `hex 8 ws 0x81 1 rl, carry, 0x81 1 rrc, carry, 1 rrc, 0x1234 bswap, 16 ws 0x0100 clz`,
+		want:  []string{"0x03", "0x01", "0xc0", "0x01", "0x80", "0x34", "0x0007"},
+	},
+	{
+		name:  "word-size-fraction",
+		input: `12.5 ws`,
+		fail:  "ws: invalid operand x=12.5",
+	},
+	{
+		name:  "word-size-zero",
+		input: `0 ws`,
+		fail:  "ws: invalid operand x=0",
+	},
+	{
+		name:  "word-bit-range",
+		input: `hex 8 ws 0 8 sb`,
+		fail:  "sb: invalid bit number x=0x8",
+	},
+	{
+		name:  "word-bit-test-range",
+		input: `hex 8 ws 0xff 0x40 b?`,
+		fail:  "b?: invalid bit number x=0x40",
+	},
+	{
+		name:  "big-rotate",
+		input: `"big" mode 129 1 rl`,
+		fail:  "rl: not supported for big integers",
+	},
+	{
+		name:  "big-swap",
+		input: `"big" mode 4660 bswap`,
+		fail:  "bswap: not supported for big integers",
+	},
+	{
+		name:  "word-divide",
+		input: `hex 7 0 /`,
+		fail:  "div: division by zero",
+	},
+	{
+		name:  "date",
+		input: `2026-10-17 90 +, 2026-10-17 -, 2026-10-17T18:00 2026-10-17 -, 2 fix 2026-10-17T13:45:30 0.5 +, dow, "2026-10-17" date dow`,
+		want:  []string{"2027-01-15", "90", "0.75", "2026-10-18T01:45:30.00", "7.00", "6.00"},
+	},
+	{
+		name:  "date-business",
+		input: `2026-10-16 2026-10-23 bdays, 2026-10-23 2026-10-16 bdays, 2026-10-16 1 addbd, -5 addbd, 2026-10-16 2026-10-17 <`,
+		want:  []string{"5", "-5", "2026-10-19", "2026-10-12", "1"},
+	},
+	{
+		name:  "date-business-far",
+		input: `2026-10-17 2610 addbd, -2610 addbd, 2026-10-17 1e6 addbd`,
+		want:  []string{"2036-10-17", "2026-10-16", "5859-11-11"},
+	},
+	{
+		name:  "date-business-huge",
+		input: `2026-10-17 1e300 addbd`,
+		fail:  "addbd: invalid operands y=time.Date(2026, time.October, 17, 0, 0, 0, 0, time.UTC), x=1e+300",
+	},
+	{
+		name:  "date-add-nan",
+		input: `2026-10-17 0 0 / +`,
+		fail:  "add: invalid number of days NaN",
+	},
+	{
+		name:  "date-sub-huge",
+		input: `2026-10-17 1e10 -`,
+		fail:  "sub: invalid number of days 1e+10",
+	},
+	{
+		name:  "date-invalid",
+		input: `2026-02-30`,
+		err:   "invalid date",
+	},
+	{
+		name:  "date-mismatch",
+		input: `2026-10-17 2 *`,
+		fail:  "mul: mismatched operands y=time.Date(2026, time.October, 17, 0, 0, 0, 0, time.UTC), x=2",
+	},
+	{
+		name:  "units",
+		input: `9.81 m/s^2 2 s *, 1 km 500 m +, 2 m sqr, sqrt, 3 ft 1 m <, 4 fix 3 ft "m" to, 1 mi "km" to mag, 1 ft 1 m / "" to, 5 "kg" to, 2 m 3 **`,
+		want:  []string{"19.62 m/s", "1.5 km", "4 m^2", "2 m", "1", "0.9144 m", "1.6093", "0.3048", "5.0000 kg", "8.0000 m^3"},
+	},
+	{
+		name:  "units-words",
+		input: `:g 2 *; 1 g, 1 kg 500 "g" to +`,
+		want:  []string{"2", "1.5 kg"},
+	},
+	{
+		name:  "units-mismatch",
+		input: `1 m 1 s +`,
+		fail:  "add: incompatible units s vs m",
+	},
+	{
+		name:  "units-invalid",
+		input: `1 m "s" to`,
+		fail:  "to: incompatible units m vs s",
+	},
+	{
+		name:  "units-dimensionless",
+		input: `1 ft 2 +`,
+		fail:  "add: incompatible units dimensionless vs ft",
+	},
+	{
+		name:  "units-no-dimensions",
+		input: `1 Hz 2 s *, 1 +, 1 ft/m`,
+		want:  []string{"2", "3", "0.3048"},
+	},
+	{
+		name:  "units-power",
+		input: `2 m^9 1 m *`,
+		fail:  "mul: power of a unit out of range m^10",
+	},
+	{
+		name:  "units-power-huge",
+		input: `3 "m^9223372036854775807" to`,
+		fail:  `to: invalid power "m^9223372036854775807"`,
+	},
+	{
+		name:  "units-reciprocal",
+		input: `1 1/s, 60 * "Hz" to, 2 1/s 3 s *, 3 1/s^2 sqrt`,
+		want:  []string{"1 1/s", "60 Hz", "6", "1.7320508075688772 1/s"},
+	},
+	{
+		name:  "constants",
+		input: `"c" const, "k_B" const, "alpha" const, 1 kg "c" const sqr * "J" to, "planck" const? depth`,
+		want:  []string{"2.99792458e+08 m/s", "1.380649e-23 J/K", "0.0072973525693", "8.987551787368176e+16 J", "4"},
+	},
+	{
+		name:  "constants-unknown",
+		input: `"x" const`,
+		fail:  `const: unknown constant "x"`,
+	},
+	{
+		name:  "constants-no-match",
+		input: `"xyzzy" const?`,
+		fail:  `const?: no constant matches "xyzzy"`,
+	},
+	{
+		name:  "distributions",
+		input: `4 fix 0 0 1 normpdf, 1.96 0 1 normcdf, 0.975 10 2 norminv, 2 5 tpdf, 0.975 5 tinv, 3.84 1 chicdf, 0.95 10 chiinv, 3 5 10 fcdf, 0.95 5 10 finv`,
+		want:  []string{"0.3989", "0.9750", "13.9199", "0.0651", "2.5706", "0.9500", "18.3070", "0.9344", "3.3258"},
+	},
+	{
+		name:  "distributions-discrete",
+		input: `4 fix 3 10 0.5 binpdf, 3 10 0.5 bincdf, 0.5 10 0.5 bininv, 2 3 poipdf, 2 3 poicdf, 0.99 3 poiinv, 0.5 0 -1 norminv`,
+		want:  []string{"0.1172", "0.1719", "5.0000", "0.2240", "0.4232", "8.0000", "NaN"},
+	},
+	{
+		name:  "distributions-large",
+		input: `4 fix 0.5 20000000 poiinv, 0.999 20000000 poiinv, 19999999 20000000 poicdf, 0.999 1e9 0.5 bininv`,
+		want:  []string{"20000000.0000", "20013821.0000", "0.5000", "500048861.0000"},
+	},
+	{
+		name:  "hyperbolic",
+		input: `6 fix 1 sinh, 1 cosh, 0.5 tanh atanh, 1 asinh, 2 acosh, "complex" mode 0.5 acosh`,
+		want:  []string{"1.175201", "1.543081", "0.500000", "0.881374", "1.316958", "(0.000000+1.047198i)"},
+	},
+	{
+		name:  "special",
+		input: `6 fix 0.5 erf, 0.5 erfc, 0.5 erfinv, 10 lgamma, 5 gamma, 2 3 beta, 2 1 igamma, 2 1 igammac, 2 3 0.4 ibeta`,
+		want:  []string{"0.520500", "0.479500", "0.476936", "12.801827", "24.000000", "0.083333", "0.264241", "0.735759", "0.524800"},
+	},
+	{
+		name:  "bessel",
+		input: `6 fix 1 j0, 1 j1, 2 1 jn, 1 y0, 1 y1, 2 1 yn`,
+		want:  []string{"0.765198", "0.440051", "0.114903", "0.088257", "-0.781213", "-1.650683"},
+	},
+	{
+		name:  "bessel-order",
+		input: `2.5 1 jn`,
+		fail:  "jn: invalid order y=2.5",
+	},
+	{
+		name:  "hms",
+		input: `4 fix 1.5 hms, 1.3045 hr, 1.3045 2.4530 hms+, 1.3045 2.4530 hms-, 0.1 hms hr`,
+		want:  []string{"1.3000", "1.5125", "4.1615", "-1.1445", "0.1000"},
+	},
+	{
+		name:  "dms",
+		input: `4 fix "rad" mode 12.3015 dms, 30° sin, 12°30'15" rad, -12°30.5', 45°0'0" tan`,
+		want:  []string{"12.5042", "0.5000", "0.2182", "-12.5083", "1.0000"},
+	},
+	{
+		name:  "dms-invalid",
+		input: `12°61'`,
+		err:   "invalid angle",
+	},
+	{
+		name:  "tvm",
+		input: `2 fix 360 n 0.5 rate 100000 pv 0 fv, pmt?, rate?, n?, 0 pv pv?, "begin" mode pmt?, clrfin 10 n 100 pv pmt?`,
+		want:  []string{"0.00", "-599.55", "0.50", "360.00", "100000.00", "-596.57", "-10.00"},
+	},
+	{
+		name:  "cash-flow",
+		input: `2 fix -1000 cf0 300 cfj 400 cfj 500 cfj 5 rate, npv, irr, -1000 cf0 300 cfj 4 nj irr, $r_rate @`,
+		want:  []string{"5.00", "80.44", "8.90", "7.71", "7.71"},
+	},
+	{
+		name:  "cash-flow-no-solution",
+		input: `100 cf0 200 cfj irr`,
+		fail:  "irr: no solution",
+	},
+	{
+		name:  "amortize",
+		input: `2 fix 360 n 0.5 rate 100000 pv 0 fv pmt?, 1 12 amort, drop, drop, 13 24 amort, "begin" mode pmt? 2 2 amort`,
+		want:  []string{"-599.55", "98771.99", "-1228.01", "-5966.59", "97468.24", "99303.88"},
+	},
+	{
+		name:  "amortize-huge",
+		input: `360 n 0.5 rate 100000 pv 1 1e20 amort`,
+		fail:  "amort: invalid range 1-1e+20",
+	},
+	{
+		name:  "amortize-fraction",
+		input: `1.5 12 amort`,
+		fail:  "amort: invalid range 1.5-12",
+	},
+	{
+		name:  "amortize-nan",
+		input: `0 0 / 12 amort`,
+		fail:  "amort: invalid range NaN-12",
+	},
+	{
+		name:  "cash-flow-count-huge",
+		input: `-1000 cf0 300 cfj 1e9 nj`,
+		fail:  "nj: invalid count 1e+09",
+	},
+	{
+		name:  "cash-flow-count-nan",
+		input: `-1000 cf0 300 cfj 0 0 / nj`,
+		fail:  "nj: invalid count NaN",
+	},
+	{
+		name:  "tvm-no-solution",
+		input: `10 n 5 rate 100 pv 100 fv 0 pmt n?`,
+		fail:  "n?: no solution",
+	},
+	{
+		name:  "tvm-rate-invalid",
+		input: `-100 rate`,
+		fail:  "rate: invalid interest rate -100",
+	},
+	{
+		name:  "exact",
+		input: `"exact" mode 1 3 /, 0.1 0.2 +, 2 3 / -2 **, 1 3 / 1 3 / 1 3 / + + 1 ==, 5 2 / floor, 2 3 / recp`,
+		want:  []string{"1/3", "3/10", "9/4", "1", "2", "3/2"},
+	},
+	{
+		name:  "exact-inexact",
+		input: `"exact" mode 2 sqrt, 1 3 / inexact, 0.25 exact, 1.5 1 3 / +, 1.5 inexact 1 3 / +, 3 fix 1 3 /`,
+		want:  []string{"1.4142135623730951", "0.3333333333333333", "1/4", "11/6", "1.8333333333333333", "0.333"},
+	},
+	{
+		name:  "exact-display",
+		input: `"exact" mode mixed 7 3 /, -7 3 /, 2 3 /, 4, ratio 7 3 /, 0.5 inexact`,
+		want:  []string{"2 1/3", "-2 1/3", "2/3", "4", "7/3", "0.5"},
+	},
+	{
+		name:  "exact-stats",
+		input: `"exact" mode 1 2 ∑+ drop 3 4 ∑+, mean`,
+		want:  []string{"2", "3"},
+	},
+	{
+		name:  "precise",
+		input: `"precise" mode 30 fix 2 sqrt, 1 exp, 1 3 /, 2 0.5 **, 2 100 ** 0 fix, 1 3 / 12345 * 5 eng`,
+		want: []string{
+			"1.414213562373095048801688724210",
+			"2.718281828459045235360287471353",
+			"0.333333333333333333333333333333",
+			"1.414213562373095048801688724210",
+			"1267650600228229401496703205376",
+			"4.11500e+03",
+		},
+	},
+	{
+		name:  "precise-trig",
+		input: `"precise" mode 30 fix 30 sin, 0.5 acos, 45 tan, rad pi, 1 atan 4 *, 100 ln 10 ln /`,
+		want: []string{
+			"0.500000000000000000000000000000",
+			"60.000000000000000000000000000000",
+			"1.000000000000000000000000000000",
+			"3.141592653589793238462643383280",
+			"3.141592653589793238462643383280",
+			"2.000000000000000000000000000000",
+		},
+	},
+	{
+		name:  "precise-fallback",
+		input: `"precise" mode -1 sqrt, 1 0 /, "complex" mode -4 sqrt, 64 prec free 1 3 /, status`,
+		want:  []string{"NaN", "+Inf", "(0+2i)", "0.33333333333333333334", "0.33333333333333333334"},
+	},
+	{
+		name:  "string-concat",
+		input: `"out" "-" + 3 str + ".txt" +, len`,
+		want:  []string{"out-3.txt", "9"},
+	},
+	{
+		name:  "string-case",
+		input: `"hello world" 6 5 substr, upper, "Hello" lower, "héllo" "l" find, "hello" "z" find`,
+		want:  []string{"world", "WORLD", "hello", "2", "-1"},
+	},
+	{
+		name:  "string-split-join",
+		input: `"a,b,c" "," split, "-" join`,
+		want:  []string{"3", "a-b-c"},
+	},
+	{
+		name:  "string-number",
+		input: `2 fix 3 str, "2.5e3" num, hex "0x1f" num, "17" num`,
+		want:  []string{"3.00", "2500.00", "0x001f", "0x0011"},
+	},
+	{
+		name:  "string-bad-number",
+		input: `"abc" num`,
+		fail:  `num: invalid number "abc"`,
+	},
+	{
+		name:  "string-range",
+		input: `"abc" 1 4 substr`,
+		fail:  `substr: out of range 1:4 in "abc"`,
+	},
+	{
+		name:  "string-range-huge",
+		input: `"abc" 0 1e20 substr`,
+		fail:  `substr: out of range 0:1e+20 in "abc"`,
+	},
+	{
+		name:  "string-range-nan",
+		input: `"abc" 0 0 / 1 substr`,
+		fail:  `substr: out of range NaN:1 in "abc"`,
+	},
+	{
+		name:  "string-join-huge",
+		input: `"a,b" "," split 1e20 "-" join`,
+		fail:  `stack underflow`,
+	},
+	{
+		name:  "string-join-fraction",
+		input: `"a,b" "," split drop 1.5 "-" join`,
+		fail:  `join: invalid count 1.5`,
+	},
 	{
 		name:  "bitwise-xor",
 		input: `1 bin 3 ^`,
@@ -312,3 +1066,42 @@ func TestParser(t *testing.T) {
 		t.Run(st.name, st.run)
 	}
 }
+
+func TestOutput(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"print", `1 2 + . 4 .`, "3 4 "},
+		{"print-fix", `2 fix pi .`, "3.14 "},
+		{"emit-cr", `72 emit 105 emit cr`, "Hi\n"},
+		{"type", `"hello" type`, "hello"},
+		{"printf", `"pi" pi 3 "%s is %.4f (%d)\n" printf`, "pi is 3.1416 (3)\n"},
+		{"printf-display", `2 fix pi "%v%%" printf`, "3.14%"},
+		{"printf-big", `"big" mode 2 70 ** dup "%d %x" printf`, "1180591620717411303424 400000000000000000"},
+		{"printf-rational", `"exact" mode 7 2 / dup "%d %.2f" printf`, "3 3.50"},
+		{"printf-precise", `"precise" mode 2 sqrt dup "%d %.20f" printf`, "1 1.41421356237309504880"},
+		{"printf-star", `5 42 2 pi "[%*d] %.*f" printf`, "[   42] 3.14"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+
+			m := New(&out)
+			p := NewParser(m, NewScanner(ScanConfig{}, tt.name, bytes.NewBufferString(tt.input)), os.Stderr, 1, false)
+			e, _, err := p.Line()
+
+			if err != nil {
+				t.Fatalf("parse: %s", err)
+			}
+
+			if _, err = m.Eval(1, e); err != nil {
+				t.Fatalf("eval: %s", err)
+			}
+
+			if got := out.String(); got != tt.want {
+				t.Errorf("wanted %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package oak
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+var (
+	// Print pops the top of stack and prints it (as it
+	// would be shown in the current display mode) followed
+	// by a space.
+	Print ExprFunc = func(m *Machine) error {
+		if len(m.stack) < 1 {
+			return errUnderflow
+		}
+
+		fmt.Fprint(m.output, m.Pop(), " ")
+		return nil
+	}
+
+	// Emit pops a number and prints it as a character.
+	Emit ExprFunc = func(m *Machine) error {
+		if len(m.stack) < 1 {
+			return errUnderflow
+		}
+
+		x := m.Pop()
+		f, ok := x.float()
+
+		if !ok {
+			return fmt.Errorf("emit: invalid operand x=%#v", x.V)
+		}
+
+		fmt.Fprint(m.output, string(rune(f)))
+		return nil
+	}
+
+	NewLine ExprFunc = func(m *Machine) error {
+		fmt.Fprintln(m.output)
+		return nil
+	}
+
+	// Type pops a string and prints it as is.
+	Type ExprFunc = func(m *Machine) error {
+		if len(m.stack) < 1 {
+			return errUnderflow
+		}
+
+		if t := m.Top(); t.T != stringer {
+			return fmt.Errorf("type: invalid operand x=%#v", t.V)
+		}
+
+		fmt.Fprint(m.output, m.Pop().V)
+		return nil
+	}
+
+	// Printf pops a format string and then one value for
+	// each verb in the format (and for each * width or
+	// precision), and prints them; the values are taken
+	// in the order they were pushed.
+	Printf ExprFunc = func(m *Machine) error {
+		if len(m.stack) < 1 {
+			return errUnderflow
+		}
+
+		if t := m.Top(); t.T != stringer {
+			return fmt.Errorf("printf: invalid format %#v", t.V)
+		}
+
+		f := unescape(m.Pop().V.(string))
+		verbs := formatVerbs(f)
+
+		if len(verbs) > len(m.stack) {
+			return errUnderflow
+		}
+
+		args := make([]interface{}, len(verbs))
+
+		for i := len(verbs) - 1; i >= 0; i-- {
+			args[i] = m.Pop().formatArg(verbs[i])
+		}
+
+		fmt.Fprintf(m.output, f, args...)
+		return nil
+	}
+)
+
+// formatVerbs returns the verb of each conversion
+// in a format string (ignoring %%), with a * before
+// it for each width or precision taken from a value.
+func formatVerbs(f string) []rune {
+	var verbs []rune
+
+	for i := 0; i < len(f); i++ {
+		if f[i] != '%' {
+			continue
+		}
+
+		// skip over flags, width, and precision
+
+		for i++; i < len(f) && strings.IndexByte("+-# 0123456789.*", f[i]) >= 0; i++ {
+			if f[i] == '*' {
+				verbs = append(verbs, '*')
+			}
+		}
+
+		if i < len(f) && f[i] != '%' {
+			verbs = append(verbs, rune(f[i]))
+		}
+	}
+
+	return verbs
+}
+
+// formatArg returns the value in the form that
+// best suits the verb it's to be printed with (or
+// an int for a * width or precision).
+func (v Value) formatArg(verb rune) interface{} {
+	switch verb {
+	case '*':
+		if f, ok := v.float(); ok {
+			return int(f)
+		}
+
+	case 'd', 'x', 'X', 'o', 'b', 'c':
+		var n *big.Int
+
+		switch v.T {
+		case floater:
+			return int64(v.V.(float64))
+
+		case integer:
+			return v.V
+
+		case bigint:
+			n = v.V.(*big.Int)
+
+		case rational:
+			r := v.V.(*big.Rat)
+			n = new(big.Int).Quo(r.Num(), r.Denom())
+
+		case bigfloat:
+			if n, _ = v.V.(*big.Float).Int(nil); n == nil {
+				return v.String() // infinite
+			}
+
+		default:
+			return v.V
+		}
+
+		if verb == 'c' {
+			return n.Int64()
+		}
+
+		return n
+
+	case 'e', 'E', 'f', 'F', 'g', 'G':
+		if v.T == bigfloat {
+			return v.V
+		}
+
+		if f, ok := v.float(); ok {
+			return f
+		}
+
+	case 's', 'v', 'q':
+		if v.T != stringer {
+			return v.String()
+		}
+	}
+
+	return v.V
+}
+
+// unescape interprets backslash escapes such as \n
+// or \t in a string (if it's well-formed).
+func unescape(s string) string {
+	if u, err := strconv.Unquote(`"` + s + `"`); err == nil {
+		return u
+	}
+
+	return s
+}
@@ -0,0 +1,244 @@
+package oak
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+)
+
+// ratOps are the binary operations which give exact
+// results on rational numbers, by name; a nil result
+// means there's no exact answer.
+var ratOps = map[string]func(y, x *big.Rat) *big.Rat{
+	"add": func(y, x *big.Rat) *big.Rat { return new(big.Rat).Add(y, x) },
+	"sub": func(y, x *big.Rat) *big.Rat { return new(big.Rat).Sub(y, x) },
+	"mul": func(y, x *big.Rat) *big.Rat { return new(big.Rat).Mul(y, x) },
+	"div": func(y, x *big.Rat) *big.Rat {
+		if x.Sign() == 0 {
+			return nil
+		}
+
+		return new(big.Rat).Quo(y, x)
+	},
+	"pow": ratPower,
+	"max": func(y, x *big.Rat) *big.Rat {
+		if y.Cmp(x) > 0 {
+			return y
+		}
+
+		return x
+	},
+	"min": func(y, x *big.Rat) *big.Rat {
+		if y.Cmp(x) < 0 {
+			return y
+		}
+
+		return x
+	},
+}
+
+// ratFuncs are the unary functions which give exact
+// results on rational numbers, by name.
+var ratFuncs = map[string]func(x *big.Rat) *big.Rat{
+	"abs":  func(x *big.Rat) *big.Rat { return new(big.Rat).Abs(x) },
+	"sqr":  func(x *big.Rat) *big.Rat { return new(big.Rat).Mul(x, x) },
+	"cube": func(x *big.Rat) *big.Rat { return new(big.Rat).Mul(x, new(big.Rat).Mul(x, x)) },
+	"recp": func(x *big.Rat) *big.Rat {
+		if x.Sign() == 0 {
+			return nil
+		}
+
+		return new(big.Rat).Inv(x)
+	},
+	"trunc": func(x *big.Rat) *big.Rat {
+		return new(big.Rat).SetInt(new(big.Int).Quo(x.Num(), x.Denom()))
+	},
+	"floor": func(x *big.Rat) *big.Rat {
+		return new(big.Rat).SetInt(new(big.Int).Div(x.Num(), x.Denom()))
+	},
+	"ceil": func(x *big.Rat) *big.Rat {
+		q := new(big.Int).Div(x.Num(), x.Denom())
+
+		if !x.IsInt() {
+			q.Add(q, big.NewInt(1))
+		}
+
+		return new(big.Rat).SetInt(q)
+	},
+	"frac": func(x *big.Rat) *big.Rat {
+		t := new(big.Int).Quo(x.Num(), x.Denom())
+		return new(big.Rat).Sub(x, new(big.Rat).SetInt(t))
+	},
+}
+
+var (
+	// Exact converts a number on top of stack to a rational
+	// number, using the shortest decimal form of a float.
+	Exact ExprFunc = func(m *Machine) error {
+		if len(m.stack) < 1 {
+			return errUnderflow
+		}
+
+		x := m.PopX()
+		r, ok := x.rat()
+
+		if !ok {
+			f, ok := x.float()
+
+			if !ok {
+				return fmt.Errorf("exact: invalid operand x=%#v", x.V)
+			}
+
+			if r, ok = new(big.Rat).SetString(strconv.FormatFloat(f, 'g', -1, 64)); !ok {
+				return fmt.Errorf("exact: invalid operand x=%#v", x.V)
+			}
+		}
+
+		m.Push(m.makeRatVal(r))
+		return nil
+	}
+
+	// Inexact converts a number on top of stack to a float.
+	Inexact ExprFunc = func(m *Machine) error {
+		if len(m.stack) < 1 {
+			return errUnderflow
+		}
+
+		x := m.PopX()
+		f, ok := x.float()
+
+		if !ok {
+			return fmt.Errorf("inexact: invalid operand x=%#v", x.V)
+		}
+
+		m.Push(m.makeFloatVal(f))
+		return nil
+	}
+)
+
+// RatLiteral returns an expression for a decimal literal,
+// which is a rational number in exact mode, or else the
+// number as it would otherwise be parsed.
+func RatLiteral(r *big.Rat, other Expr) ExprFunc {
+	return func(m *Machine) error {
+		if m.num == rationals {
+			m.Push(m.makeRatVal(r))
+			return nil
+		}
+
+		return other.Eval(m)
+	}
+}
+
+// ratPower raises y to an integer power x; there's no
+// exact result for a fractional power.
+func ratPower(y, x *big.Rat) *big.Rat {
+	if !x.IsInt() || !x.Num().IsInt64() {
+		return nil
+	}
+
+	n := x.Num().Int64()
+
+	if n < 0 {
+		if y.Sign() == 0 {
+			return nil
+		}
+
+		y, n = new(big.Rat).Inv(y), -n
+	}
+
+	e := big.NewInt(n)
+	a := new(big.Int).Exp(y.Num(), e, nil)
+	b := new(big.Int).Exp(y.Denom(), e, nil)
+
+	return new(big.Rat).SetFrac(a, b)
+}
+
+// binaryRat applies an operation to rational numbers, giving
+// an exact result where there is one, or else a float.
+func (m *Machine) binaryRat(op string, f func(float64, float64) float64, y, x *Value) (Value, error) {
+	a, ok1 := y.rat()
+	b, ok2 := x.rat()
+
+	if ok1 && ok2 {
+		if op == "pow" && b.IsInt() {
+			e := new(big.Int).Abs(b.Num())
+
+			if tooLarge(a.Num(), e) || tooLarge(a.Denom(), e) {
+				return Value{}, fmt.Errorf("%s: %w", op, errTooLarge)
+			}
+		}
+
+		if g, ok := ratOps[op]; ok {
+			if r := g(a, b); r != nil {
+				return m.makeRatVal(r), nil
+			}
+		}
+	}
+
+	// there's no exact result, so we'll use floats
+
+	c, ok1 := y.float()
+	d, ok2 := x.float()
+
+	if !ok1 || !ok2 {
+		return Value{}, fmt.Errorf("%s: mismatched operands y=%#v, x=%#v", op, y.V, x.V)
+	}
+
+	return m.makeFloatVal(f(c, d)), nil
+}
+
+// unaryRat applies a function to a rational number, giving
+// an exact result where there is one, or else a float.
+func (m *Machine) unaryRat(op string, f func(float64) float64, x *Value) Value {
+	if g, ok := ratFuncs[op]; ok {
+		if r := g(x.V.(*big.Rat)); r != nil {
+			return m.makeRatVal(r)
+		}
+	}
+
+	a, _ := x.float()
+	return m.makeFloatVal(f(a))
+}
+
+// rat returns the value as a rational number, or false
+// if it isn't exact (i.e., it's a float).
+func (v Value) rat() (*big.Rat, bool) {
+	switch v.T {
+	case rational:
+		return v.V.(*big.Rat), true
+
+	case bigint:
+		return new(big.Rat).SetInt(v.V.(*big.Int)), true
+
+	case integer:
+		return new(big.Rat).SetUint64(uint64(v.V.(uint))), true
+	}
+
+	return nil, false
+}
+
+// formatRat shows a rational number as a fraction (in free,
+// ratio, or mixed display), or else as a float.
+func (m *Machine) formatRat(r *big.Rat) string {
+	switch m.disp {
+	case free, ratio:
+		return r.RatString()
+
+	case mixed:
+		if r.IsInt() {
+			return r.RatString()
+		}
+
+		w, f := new(big.Int).QuoRem(r.Num(), r.Denom(), new(big.Int))
+
+		if w.Sign() == 0 {
+			return r.RatString()
+		}
+
+		return fmt.Sprintf("%s %s/%s", w, f.Abs(f), r.Denom())
+	}
+
+	f, _ := r.Float64()
+	return m.formatFloat(f)
+}
@@ -4,15 +4,25 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"math/big"
+	"time"
 )
 
 // Settings is used to save internal settings.
 type Settings struct {
-	Base     radix   `json:"base"`
-	Digits   uint    `json:"digits"`
-	Display  display `json:"display_mode"`
-	Mode     mode    `json:"trig_mode"`
-	Autosave string  `json:"autosave"`
+	Base     radix      `json:"base"`
+	Radix    int        `json:"radix,omitempty"`
+	Digits   uint       `json:"digits"`
+	Display  display    `json:"display_mode"`
+	Mode     mode       `json:"trig_mode"`
+	MaxLoop  uint       `json:"max_loops,omitempty"`
+	Complex  bool       `json:"complex,omitempty"`
+	Numeric  numeric    `json:"number_mode,omitempty"`
+	Prec     uint       `json:"precision,omitempty"`
+	WordSize uint       `json:"word_size,omitempty"`
+	Comp     complement `json:"complement,omitempty"`
+	Begin    bool       `json:"begin,omitempty"`
+	Autosave string     `json:"autosave"`
 }
 
 // MachineImage is used to save the machine's state;
@@ -24,6 +34,9 @@ type MachineImage struct {
 	Vars   map[string]*Symbol `json:"vars,omitempty"`
 	Words  map[string]*Word   `json:"words,omitempty"`
 	Stats  []*Value           `json:"stats,omitempty"`
+	TVM    []*Value           `json:"tvm,omitempty"`
+	Flows  []cashFlow         `json:"flows,omitempty"`
+	Data   []dataPoint        `json:"data,omitempty"`
 	Status Settings           `json:"status"`
 }
 
@@ -36,11 +49,22 @@ func (m *Machine) SaveToFile(fn string) error {
 		LastX: m.x,
 		Words: m.words,
 		Stats: m.stats,
+		TVM:   m.tvm,
+		Flows: m.flows,
+		Data:  m.data,
 		Status: Settings{
-			Digits:  m.digits,
-			Display: m.disp,
-			Base:    m.base,
-			Mode:    m.mode,
+			Digits:   m.digits,
+			Display:  m.disp,
+			Base:     m.base,
+			Radix:    m.nbase,
+			Mode:     m.mode,
+			MaxLoop:  m.maxLoop,
+			Complex:  m.cplx,
+			Numeric:  m.num,
+			Prec:     m.prec,
+			WordSize: m.ints.size,
+			Comp:     m.ints.comp,
+			Begin:    m.begin,
 		},
 	}
 
@@ -124,10 +148,33 @@ func (m *Machine) LoadFromFile(fn string) error {
 		}
 	}
 
+	if len(mi.TVM) == int(nfreg) {
+		m.tvm = mi.TVM
+
+		for _, v := range m.tvm {
+			v.m = m
+		}
+
+		// the saved variables are copies, so
+		// they must be linked to the registers
+
+		m.linkTVM()
+	}
+
+	m.flows = mi.Flows
+	m.data = mi.Data
 	m.base = mi.Status.Base
+	m.nbase = mi.Status.Radix
 	m.digits = mi.Status.Digits
 	m.disp = mi.Status.Display
 	m.mode = mi.Status.Mode
+	m.maxLoop = mi.Status.MaxLoop
+	m.cplx = mi.Status.Complex
+	m.num = mi.Status.Numeric
+	m.prec = mi.Status.Prec
+	m.ints.size = mi.Status.WordSize
+	m.ints.comp = mi.Status.Comp
+	m.begin = mi.Status.Begin
 
 	return nil
 }
@@ -135,6 +182,9 @@ func (m *Machine) LoadFromFile(fn string) error {
 func (m *Machine) resetForLoad() {
 	m.stack = nil
 	m.stats = nil
+	m.tvm = nil
+	m.flows = nil
+	m.data = nil
 	m.words = make(map[string]*Word, 1024)
 	m.x = nil
 
@@ -145,6 +195,124 @@ func (m *Machine) resetForLoad() {
 	}
 }
 
+// MarshalJSON encodes a value for a machine image; complex
+// numbers are saved as a pair, since JSON can't encode them,
+// and high-precision floats along with their precision.
+func (v Value) MarshalJSON() ([]byte, error) {
+	type plain Value // without these methods
+
+	p := plain(v)
+
+	switch x := v.V.(type) {
+	case complex128:
+		p.V = [2]float64{real(x), imag(x)}
+
+	case *big.Float:
+		p.V = savedFloat{x.Prec(), x.Text('g', -1)}
+	}
+
+	return json.Marshal(p)
+}
+
+// savedFloat is a high-precision float in a machine image.
+type savedFloat struct {
+	P uint   `json:"prec"`
+	S string `json:"text"`
+}
+
+// UnmarshalJSON decodes a value saved in a machine image,
+// restoring its Go type from the tag (otherwise, all numbers
+// would decode as floats, and vectors as generic lists).
+func (v *Value) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		T tag             `json:"tag"`
+		M mode            `json:"mode"`
+		V json.RawMessage `json:"value"`
+	}
+
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	var err error
+
+	v.T, v.M = raw.T, raw.M
+
+	switch raw.T {
+	case floater:
+		var f float64
+		err = json.Unmarshal(raw.V, &f)
+		v.V = f
+
+	case integer:
+		var i uint
+		err = json.Unmarshal(raw.V, &i)
+		v.V = i
+
+	case stringer:
+		var s string
+		err = json.Unmarshal(raw.V, &s)
+		v.V = s
+
+	case symbol:
+		var s Symbol
+		err = json.Unmarshal(raw.V, &s)
+		v.V = &s
+
+	case word:
+		var w Word
+		err = json.Unmarshal(raw.V, &w)
+		v.V = &w
+
+	case vector:
+		var f []float64
+		err = json.Unmarshal(raw.V, &f)
+		v.V = f
+
+	case matrix:
+		var a [][]float64
+		err = json.Unmarshal(raw.V, &a)
+		v.V = a
+
+	case complexer:
+		var z [2]float64
+		err = json.Unmarshal(raw.V, &z)
+		v.V = complex(z[0], z[1])
+
+	case bigint:
+		var n big.Int
+		err = json.Unmarshal(raw.V, &n)
+		v.V = &n
+
+	case rational:
+		var r big.Rat
+		err = json.Unmarshal(raw.V, &r)
+		v.V = &r
+
+	case bigfloat:
+		var f savedFloat
+
+		if err = json.Unmarshal(raw.V, &f); err == nil {
+			v.V, _, err = big.ParseFloat(f.S, 10, f.P, big.ToNearestEven)
+		}
+
+	case datetime:
+		var t time.Time
+		err = json.Unmarshal(raw.V, &t)
+		v.V = t
+
+	case quantity:
+		var q measure
+		err = json.Unmarshal(raw.V, &q)
+		v.V = q
+
+	default:
+		return fmt.Errorf("invalid tag %d", raw.T)
+	}
+
+	return err
+}
+
 var (
 	// Save pops a filename and saves the machine to that file.
 	Save ExprFunc = func(m *Machine) error {
@@ -1,6 +1,7 @@
 package oak
 
 import (
+	"bytes"
 	"io/ioutil"
 	"os"
 	"testing"
@@ -102,3 +103,151 @@ func TestSaveLoad(t *testing.T) {
 		t.Errorf("invalid result: %#v", r)
 	}
 }
+
+type saveTest struct {
+	name  string
+	setup string   // run before saving
+	input string   // run after loading into a new machine
+	want  []string // one for each line of input
+}
+
+// evalLines runs the lines of an input (separated by commas)
+// on the machine, starting at the given line number, and
+// returns the result of each.
+func evalLines(t *testing.T, m *Machine, input string, line int) []string {
+	t.Helper()
+
+	b := bytes.NewBufferString(input)
+	p := NewParser(m, NewScanner(ScanConfig{}, "test", b), os.Stderr, line, false)
+
+	var result []string
+
+	for {
+		got, _, err := p.Line()
+
+		if err != nil {
+			t.Fatalf("couldn't parse %q: %s", input, err)
+		}
+
+		if len(got) == 0 || got[0] == nil {
+			return result
+		}
+
+		top, err := m.Eval(line, got)
+
+		if err != nil {
+			t.Fatalf("couldn't eval %q: %s", input, err)
+		}
+
+		s, _ := top.(string)
+		result = append(result, s)
+		line++
+	}
+}
+
+// run saves a machine after the setup, and reads it into a
+// new, clean machine, to see if the input still works.
+func (st saveTest) run(t *testing.T) {
+	file, err := ioutil.TempFile(".", "*.img")
+
+	if err != nil {
+		t.Fatalf("tmp file: %s", err)
+	}
+
+	file.Close()
+	defer os.Remove(file.Name())
+
+	m1 := New(os.Stdout)
+	n := len(evalLines(t, m1, st.setup, 1))
+
+	if err = m1.SaveToFile(file.Name()); err != nil {
+		t.Fatalf("save: %s", err)
+	}
+
+	m2 := New(os.Stdout)
+
+	if err = m2.LoadFromFile(file.Name()); err != nil {
+		t.Fatalf("load: %s", err)
+	}
+
+	got := evalLines(t, m2, st.input, n+1)
+
+	if len(got) != len(st.want) {
+		t.Fatalf("wanted %d results, got %d: %q", len(st.want), len(got), got)
+	}
+
+	for i, r := range got {
+		if r != st.want[i] {
+			t.Errorf("line %d: wanted %q, got %q", i, st.want[i], r)
+		}
+	}
+}
+
+var saveTests = []saveTest{
+	{
+		name:  "control",
+		setup: `:f if 10 else 20 then;`,
+		input: `1 f, 0 f`,
+		want:  []string{"10", "20"},
+	},
+	{
+		name:  "vector",
+		setup: `1 fix [1 2 3], [[1 2] [3 4]]`,
+		input: `trn, drop 2 *`,
+		want:  []string{"[[1.0 3.0]\n [2.0 4.0]]", "[2.0 4.0 6.0]"},
+	},
+	{
+		name:  "complex",
+		setup: `"complex" mode 1 -2 cplx`,
+		input: `conj, -4 sqrt`,
+		want:  []string{"(1+2i)", "(0+2i)"},
+	},
+	{
+		name:  "big",
+		setup: `"big" mode 2 100 **`,
+		input: `1 +`,
+		want:  []string{"1267650600228229401496703205377"},
+	},
+	{
+		name:  "rational",
+		setup: `"exact" mode mixed 1 3 /`,
+		input: `1 3 / 1 3 / + + 1 3 / +`,
+		want:  []string{"1 1/3"},
+	},
+	{
+		name:  "precise",
+		setup: `"precise" mode 128 prec 30 fix 2 sqrt`,
+		input: `dup *, free 1 3 /`,
+		want:  []string{"2.000000000000000000000000000000", "0.333333333333333333333333333333333333334"},
+	},
+	{
+		name:  "tvm",
+		setup: `"begin" mode 2 fix 36 n 1 rate 10000 pv -1000 cf0 1100 cfj`,
+		input: `pmt?, $r_pmt @, irr`,
+		want:  []string{"-328.85", "-328.85", "10.00"},
+	},
+	{
+		name:  "date",
+		setup: `2026-10-17T13:45`,
+		input: `1 +`,
+		want:  []string{"2026-10-18T13:45:00"},
+	},
+	{
+		name:  "units",
+		setup: `3 m/s`,
+		input: `2 *`,
+		want:  []string{"6 m/s"},
+	},
+	{
+		name:  "data",
+		setup: `7 ∑+ drop 1 ∑+ drop 4 ∑+ drop`,
+		input: `median`,
+		want:  []string{"4"},
+	},
+}
+
+func TestSaveLoadState(t *testing.T) {
+	for _, st := range saveTests {
+		t.Run(st.name, st.run)
+	}
+}
@@ -11,7 +11,6 @@ import (
 
 // Config holds some configuration data for the scanner.
 type ScanConfig struct {
-	Base        int // TODO - remove; scanner needs to accept digits based on prefix only
 	Line        int
 	Interactive bool
 }
@@ -20,6 +19,8 @@ type ScanConfig struct {
 type Scanner struct {
 	tokens chan token.Token // channel of scanned items
 	config ScanConfig
+	base   int    // the input base, as set by the parser
+	last   string // the last number scanned, if it was the last token
 	r      io.ByteReader
 	done   bool
 	name   string // the name of the input; used only for error reports
@@ -44,6 +45,7 @@ func NewScanner(c ScanConfig, name string, r io.ByteReader) *Scanner {
 		line:   c.Line,
 		tokens: make(chan token.Token, 2), // We need a little room to save tokens.
 		config: c,
+		base:   10,
 		state:  lexAny,
 	}
 
@@ -81,6 +83,11 @@ func (l *Scanner) Line() string {
 	return l.input
 }
 
+// setBase changes the input base for numbers.
+func (l *Scanner) setBase(base int) {
+	l.base = base
+}
+
 const eof = -1
 
 // stateFn represents the state of the scanner as a
@@ -165,6 +172,12 @@ func (l *Scanner) emit(t token.Type) {
 	// }
 
 	l.tokens <- token.Token{Type: t, Line: l.line, Text: s}
+	l.last = ""
+
+	if t == token.Number {
+		l.last = s
+	}
+
 	l.start = l.pos
 	l.width = 0
 
@@ -119,6 +119,70 @@ var scanTests = []scanTest{
 			{Type: token.Identifier, Line: 1, Text: "sin"},
 		},
 	},
+	{
+		name:  "radix-literal",
+		input: `0r36:zz 0x1f hex 1f dec`,
+		want: []token.Token{
+			{Type: token.Number, Line: 1, Text: "0r36:zz"},
+			{Type: token.Number, Line: 1, Text: "0x1f"},
+			{Type: token.Identifier, Line: 1, Text: "hex"},
+			{Type: token.Number, Line: 1, Text: "1f"},
+			{Type: token.Identifier, Line: 1, Text: "dec"},
+		},
+	},
+	{
+		name:  "date-literal",
+		input: `2026-10-17 2026-10-17T13:45 2026-10`,
+		want: []token.Token{
+			{Type: token.Number, Line: 1, Text: "2026-10-17"},
+			{Type: token.Number, Line: 1, Text: "2026-10-17T13:45"},
+			{Type: token.Number, Line: 1, Text: "2026"},
+			{Type: token.Operator, Line: 1, Text: "-"},
+			{Type: token.Number, Line: 1, Text: "10"},
+		},
+	},
+	{
+		name:  "unit-literal",
+		input: `9.81 m/s^2 3 ft 2 1`,
+		want: []token.Token{
+			{Type: token.Number, Line: 1, Text: "9.81 m/s^2"},
+			{Type: token.Number, Line: 1, Text: "3 ft"},
+			{Type: token.Number, Line: 1, Text: "2"},
+			{Type: token.Number, Line: 1, Text: "1"},
+		},
+	},
+	{
+		name:  "dms-literal",
+		input: `12°30'15" -1.5° hms+ hms- 1`,
+		want: []token.Token{
+			{Type: token.Number, Line: 1, Text: `12°30'15"`},
+			{Type: token.Number, Line: 1, Text: "-1.5°"},
+			{Type: token.Identifier, Line: 1, Text: "hms+"},
+			{Type: token.Identifier, Line: 1, Text: "hms-"},
+			{Type: token.Number, Line: 1, Text: "1"},
+		},
+	},
+	{
+		name:  "ident-query",
+		input: `0x10 4 b? ?`,
+		want: []token.Token{
+			{Type: token.Number, Line: 1, Text: "0x10"},
+			{Type: token.Number, Line: 1, Text: "4"},
+			{Type: token.Identifier, Line: 1, Text: "b?"},
+			{Type: token.Operator, Line: 1, Text: "?"},
+		},
+	},
+	{
+		name:  "simple-print",
+		input: `.5 2 * . .`,
+		want: []token.Token{
+			{Type: token.Number, Line: 1, Text: ".5"},
+			{Type: token.Number, Line: 1, Text: "2"},
+			{Type: token.Operator, Line: 1, Text: "*"},
+			{Type: token.Operator, Line: 1, Text: "."},
+			{Type: token.Operator, Line: 1, Text: "."},
+		},
+	},
 }
 
 func TestScanner(t *testing.T) {
@@ -3,6 +3,7 @@ package oak
 import (
 	"fmt"
 	"math"
+	"math/big"
 	"strconv"
 )
 
@@ -10,16 +11,36 @@ var (
 	// CONSTANTS
 
 	E ExprFunc = func(m *Machine) error {
+		if m.num == precise {
+			e, _ := expFloat(newFloat(m.precision()).SetInt64(1), m.precision())
+			m.Push(m.makeBigFloatVal(e))
+			return nil
+		}
+
 		m.Push(Value{floater, m.mode, math.E, m})
 		return nil
 	}
 
 	Pi ExprFunc = func(m *Machine) error {
+		if m.num == precise {
+			m.Push(m.makeBigFloatVal(piFloat(m.precision())))
+			return nil
+		}
+
 		m.Push(Value{floater, m.mode, math.Pi, m})
 		return nil
 	}
 
 	Phi ExprFunc = func(m *Machine) error {
+		if m.num == precise {
+			p := m.precision()
+			r := newFloat(p + guard).Sqrt(newFloat(p + guard).SetInt64(5))
+			r.Add(r, newFloat(p).SetInt64(1))
+
+			m.Push(m.makeBigFloatVal(newFloat(p).Quo(r, newFloat(p).SetInt64(2))))
+			return nil
+		}
+
 		m.Push(Value{floater, m.mode, math.Phi, m})
 		return nil
 	}
@@ -38,7 +59,36 @@ var (
 				return nil
 
 			case integer:
-				t.V = uint(-int(t.V.(uint)))
+				t.V = m.ints.negate(t.V.(uint))
+				return nil
+
+			case vector:
+				*t = m.unaryVector(func(f float64) float64 { return -f }, t)
+				return nil
+
+			case matrix:
+				*t = m.unaryMatrix(func(f float64) float64 { return -f }, t)
+				return nil
+
+			case complexer:
+				t.V = -t.V.(complex128)
+				return nil
+
+			case bigint:
+				t.V = new(big.Int).Neg(t.V.(*big.Int))
+				return nil
+
+			case rational:
+				t.V = new(big.Rat).Neg(t.V.(*big.Rat))
+				return nil
+
+			case bigfloat:
+				t.V = new(big.Float).Neg(t.V.(*big.Float))
+				return nil
+
+			case quantity:
+				q := t.V.(measure)
+				t.V = measure{-q.V, q.U}
 				return nil
 			}
 
@@ -59,6 +109,18 @@ var (
 				t.V = 0
 				return nil
 
+			case bigint:
+				t.V = new(big.Int)
+				return nil
+
+			case rational:
+				t.V = new(big.Rat)
+				return nil
+
+			case bigfloat:
+				t.V = newFloat(m.precision())
+				return nil
+
 			case stringer:
 				t.V = ""
 				return nil
@@ -75,6 +137,7 @@ var (
 		m.vars = make(map[string]*Symbol)
 		m.x = nil
 		m.clearStats()
+		m.clearFinance()
 		return nil
 	}
 
@@ -87,6 +150,7 @@ var (
 	ClearRegs ExprFunc = func(m *Machine) error {
 		m.x = nil
 		m.clearStats()
+		m.clearFinance()
 		return nil
 	}
 
@@ -102,6 +166,18 @@ var (
 			fmt.Printf("STAT: %s\n", m.stats)
 		}
 
+		if m.data != nil {
+			fmt.Printf("DATA: %v\n", m.data)
+		}
+
+		if m.tvm != nil {
+			fmt.Printf("TVM: %s\n", m.tvm)
+		}
+
+		if m.flows != nil {
+			fmt.Printf("CF: %v\n", m.flows)
+		}
+
 		if m.x != nil {
 			fmt.Printf("LAST: %s\n", *m.x)
 		} else {
@@ -133,8 +209,43 @@ var (
 		return nil
 	}
 
+	SetMaxLoop ExprFunc = func(m *Machine) error {
+		x := m.Pop()
+
+		if x == nil {
+			return fmt.Errorf("maxloop: empty stack")
+		}
+
+		n, ok := x.whole(math.MaxInt32)
+
+		if !ok {
+			return fmt.Errorf("maxloop: invalid operand x=%#v", x.V)
+		}
+
+		m.maxLoop = uint(n)
+		return nil
+	}
+
 	Show ExprFunc = func(m *Machine) error {
-		fmt.Fprintln(m.output, "base:", m.Base(), "mode:", m.Mode(), "display:", m.Display())
+		s := []interface{}{"base:", m.Base(), "mode:", m.Mode(), "display:", m.Display()}
+
+		if m.cplx {
+			s = append(s, "complex")
+		}
+
+		if n := m.Numeric(); n != "" {
+			s = append(s, n)
+		}
+
+		if w := m.WordMode(); w != "" {
+			s = append(s, w)
+		}
+
+		if m.begin {
+			s = append(s, "begin")
+		}
+
+		fmt.Fprintln(m.output, s...)
 		return nil
 	}
 
@@ -213,8 +324,9 @@ var (
 		}
 
 		switch x.T {
-		case floater:
-			b = uint(x.V.(float64))
+		case floater, bigint, rational, bigfloat:
+			f, _ := x.float()
+			b = uint(f)
 		case integer:
 			b = x.V.(uint)
 		case stringer:
@@ -222,18 +334,7 @@ var (
 			b = uint(i)
 		}
 
-		switch b {
-		case 2:
-			m.base = base02
-			return nil
-		case 8:
-			m.base = base08
-			return nil
-		case 10:
-			m.base = base10
-			return nil
-		case 16:
-			m.base = base16
+		if b <= 36 && m.setRadix(int(b)) {
 			return nil
 		}
 
@@ -246,11 +347,17 @@ var (
 		if t := m.Top(); t != nil {
 			switch t.T {
 			case floater:
-				t.V = uint(t.V.(float64))
+				if m.num == bigints {
+					t.V = truncBig(t.V.(float64))
+					t.T = bigint
+					return nil
+				}
+
+				t.V = m.ints.fromFloat(t.V.(float64))
 				t.T = integer
 				return nil
 
-			case integer:
+			case integer, bigint:
 				return nil
 			}
 
@@ -265,11 +372,11 @@ var (
 
 		if t := m.Top(); t != nil {
 			switch t.T {
-			case floater:
+			case floater, bigint, rational, bigfloat:
 				return nil
 
 			case integer:
-				t.V = float64(t.V.(uint))
+				t.V, _ = t.float()
 				t.T = floater
 				return nil
 			}
@@ -286,11 +393,17 @@ var (
 		if t := m.Top(); t != nil {
 			switch t.T {
 			case floater:
-				t.V = uint(t.V.(float64))
+				if m.num == bigints {
+					t.V = truncBig(t.V.(float64))
+					t.T = bigint
+					return nil
+				}
+
+				t.V = m.ints.fromFloat(t.V.(float64))
 				t.T = integer
 				return nil
 
-			case integer:
+			case integer, bigint:
 				return nil
 			}
 
@@ -306,11 +419,17 @@ var (
 		if t := m.Top(); t != nil {
 			switch t.T {
 			case floater:
-				t.V = uint(t.V.(float64))
+				if m.num == bigints {
+					t.V = truncBig(t.V.(float64))
+					t.T = bigint
+					return nil
+				}
+
+				t.V = m.ints.fromFloat(t.V.(float64))
 				t.T = integer
 				return nil
 
-			case integer:
+			case integer, bigint:
 				return nil
 			}
 
@@ -344,6 +463,16 @@ var (
 		return nil
 	}
 
+	SetRatio ExprFunc = func(m *Machine) error {
+		m.SetRatio()
+		return nil
+	}
+
+	SetMixed ExprFunc = func(m *Machine) error {
+		m.SetMixed()
+		return nil
+	}
+
 	SetEngineering ExprFunc = func(m *Machine) error {
 		x := m.Pop()
 
@@ -351,11 +480,8 @@ var (
 			return fmt.Errorf("eng: empty stack")
 		}
 
-		switch x.T {
-		case floater:
-			m.SetEngineering(uint(x.V.(float64)))
-		case integer:
-			m.SetEngineering(x.V.(uint))
+		if f, ok := x.float(); ok {
+			m.SetEngineering(uint(f))
 		}
 
 		return nil
@@ -368,11 +494,8 @@ var (
 			return fmt.Errorf("fix: empty stack")
 		}
 
-		switch x.T {
-		case floater:
-			m.SetFixed(uint(x.V.(float64)))
-		case integer:
-			m.SetFixed(x.V.(uint))
+		if f, ok := x.float(); ok {
+			m.SetFixed(uint(f))
 		}
 
 		return nil
@@ -385,11 +508,8 @@ var (
 			return fmt.Errorf("sci: empty stack")
 		}
 
-		switch x.T {
-		case floater:
-			m.SetScientific(uint(x.V.(float64)))
-		case integer:
-			m.SetScientific(x.V.(uint))
+		if f, ok := x.float(); ok {
+			m.SetScientific(uint(f))
 		}
 
 		return nil
@@ -404,20 +524,25 @@ func (m *Machine) setBuiltins() {
 		"pi":  Pi,
 		"phi": Phi,
 
+		"const":     Constant,
+		"constants": Constants,
+		"const?":    FindConstants,
+
 		// MISCELLANY
 
-		"bye":    Bye,
-		"chs":    ChangeSign,
-		"clr":    Clear,
-		"clrall": ClearAll,
-		"clrstk": ClearStack,
-		"clrreg": ClearRegs,
-		"clrvar": ClearVars,
-		"dump":   Dump,
-		"load":   Load,
-		"save":   Save,
-		"show":   Show,
-		"status": Status,
+		"bye":     Bye,
+		"chs":     ChangeSign,
+		"clr":     Clear,
+		"clrall":  ClearAll,
+		"clrstk":  ClearStack,
+		"clrreg":  ClearRegs,
+		"clrvar":  ClearVars,
+		"dump":    Dump,
+		"load":    Load,
+		"maxloop": SetMaxLoop,
+		"save":    Save,
+		"show":    Show,
+		"status":  Status,
 
 		// STACK OPERATIONS
 
@@ -440,26 +565,150 @@ func (m *Machine) setBuiltins() {
 		"hex":  Hexadecimal,
 		"oct":  Octal,
 
+		// WORD SIZE
+
+		"ws":    SetWordSize,
+		"carry": Carry,
+		"ovfl":  Overflow,
+
 		// ANGULAR MODE
 
 		"mode": SetMode,
 
 		// DISPLAY
 
-		"free": SetFree,
-		"fix":  SetFixed,
-		"eng":  SetEngineering,
-		"sci":  SetScientific,
+		"free":  SetFree,
+		"fix":   SetFixed,
+		"eng":   SetEngineering,
+		"sci":   SetScientific,
+		"ratio": SetRatio,
+		"mixed": SetMixed,
+
+		// EXACT NUMBERS
+
+		"exact":   Exact,
+		"inexact": Inexact,
+		"prec":    SetPrecision,
+
+		// LOGIC
+
+		"and": LogicalAnd,
+		"or":  LogicalOr,
+		"not": LogicalNot,
+
+		// OUTPUT
+
+		"cr":     NewLine,
+		"emit":   Emit,
+		"printf": Printf,
+		"type":   Type,
+
+		// STRINGS
+
+		"find":   Find,
+		"join":   Join,
+		"len":    Length,
+		"lower":  Lower,
+		"num":    ToNumber,
+		"split":  Split,
+		"str":    ToString,
+		"substr": Substring,
+		"upper":  Upper,
+
+		// VECTORS
+
+		"dot":  Dot,
+		"norm": VectorNorm,
+		"prod": VectorProd,
+
+		// COMPLEX NUMBERS
+
+		"arg":   Argument,
+		"conj":  Conjugate,
+		"cplx":  MakeComplex,
+		"im":    ImagPart,
+		"polar": Polar,
+		"re":    RealPart,
+		"rect":  Rectangular,
+
+		// MATRICES
+
+		"det":      Determinant,
+		"eigen":    Eigenvalues,
+		"inv":      Inverse,
+		"linsolve": LinearSolve,
+		"trn":      Transpose,
 
 		// STATS
 
-		"sum":   StatsOpAdd,
+		"sum":   VectorSum,
 		"mean":  Average,
 		"stdev": StdDeviation,
 		"sterr": StdError,
 		"line":  LinRegression,
 		"estm":  LinEstimate,
 
+		"median":   Median,
+		"quantile": Quantile,
+		"iqr":      InterQuartile,
+		"sort":     SortData,
+
+		// DISTRIBUTIONS
+
+		"normpdf": DistributionOp("norm", "pdf"),
+		"normcdf": DistributionOp("norm", "cdf"),
+		"norminv": DistributionOp("norm", "inv"),
+		"tpdf":    DistributionOp("t", "pdf"),
+		"tcdf":    DistributionOp("t", "cdf"),
+		"tinv":    DistributionOp("t", "inv"),
+		"chipdf":  DistributionOp("chi", "pdf"),
+		"chicdf":  DistributionOp("chi", "cdf"),
+		"chiinv":  DistributionOp("chi", "inv"),
+		"fpdf":    DistributionOp("f", "pdf"),
+		"fcdf":    DistributionOp("f", "cdf"),
+		"finv":    DistributionOp("f", "inv"),
+		"binpdf":  DistributionOp("bin", "pdf"),
+		"bincdf":  DistributionOp("bin", "cdf"),
+		"bininv":  DistributionOp("bin", "inv"),
+		"poipdf":  DistributionOp("poi", "pdf"),
+		"poicdf":  DistributionOp("poi", "cdf"),
+		"poiinv":  DistributionOp("poi", "inv"),
+		"ttest":   TTest,
+
+		// FINANCE
+
+		"n":      StoreTVM(tvmN),
+		"rate":   StoreTVM(tvmI),
+		"pv":     StoreTVM(tvmPV),
+		"pmt":    StoreTVM(tvmPMT),
+		"fv":     StoreTVM(tvmFV),
+		"n?":     SolveTVM(tvmN),
+		"rate?":  SolveTVM(tvmI),
+		"pv?":    SolveTVM(tvmPV),
+		"pmt?":   SolveTVM(tvmPMT),
+		"fv?":    SolveTVM(tvmFV),
+		"clrfin": ClearFinance,
+		"cf0":    CashFlow0,
+		"cfj":    CashFlowJ,
+		"nj":     CashFlowCount,
+		"npv":    NetPresentValue,
+		"irr":    InternalRate,
+		"amort":  Amortize,
+
+		// UNITS
+
+		"to":  ConvertUnit,
+		"mag": Magnitude,
+
+		// DATES
+
+		"date":  ToDate,
+		"today": Today,
+		"now":   Now,
+		"dow":   DayOfWeek,
+		"bdays": BusinessDays,
+		"addbd": AddBusinessDays,
+
 		// ADVANCED MATH
 
 		"ddx":    RunDDX,
@@ -0,0 +1,244 @@
+package oak
+
+import (
+	"fmt"
+	"math"
+)
+
+// the special functions not in the math package are found
+// with a series or continued fraction (from Numerical Recipes)
+
+const (
+	specialEps  = 1e-15
+	specialIter = 500
+	specialTiny = 1e-300
+)
+
+// gammaIter is the number of terms needed for the incomplete
+// gamma function, which grows with the square root of a (up
+// to a limit, after which the result won't be exact).
+func gammaIter(a float64) int {
+	return specialIter + int(math.Min(10*math.Sqrt(a), 1e6))
+}
+
+// BesselOp is a Bessel function of order y at x, where the
+// order must be a whole number.
+func BesselOp(op string, f func(int, float64) float64) ExprFunc {
+	g := BinaryOp(op, func(y, x float64) float64 {
+		if !isOrder(y) {
+			return math.NaN() // from a vector
+		}
+
+		return f(int(y), x)
+	})
+
+	return func(m *Machine) error {
+		if l := len(m.stack); l > 1 {
+			y := m.stack[l-2]
+
+			if n, ok := y.float(); ok && !isOrder(n) {
+				return fmt.Errorf("%s: invalid order y=%#v", op, y.V)
+			}
+		}
+
+		return g(m)
+	}
+}
+
+// isOrder reports whether a number may be the order
+// of a Bessel function.
+func isOrder(n float64) bool {
+	return n == math.Trunc(n) && math.Abs(n) <= math.MaxInt32
+}
+
+// IncompleteBeta pops x, b, and a and pushes the regularized
+// incomplete beta function I(x; a, b).
+var IncompleteBeta ExprFunc = func(m *Machine) error {
+	if len(m.stack) < 3 {
+		return errUnderflow
+	}
+
+	x := m.PopX()
+	y := m.Pop()
+	z := m.Pop()
+
+	c, ok1 := x.float()
+	b, ok2 := y.float()
+	a, ok3 := z.float()
+
+	if !ok1 || !ok2 || !ok3 {
+		return fmt.Errorf("ibeta: invalid operands z=%#v, y=%#v, x=%#v", z.V, y.V, x.V)
+	}
+
+	m.Push(m.makeFloatVal(betaInc(a, b, c)))
+	return nil
+}
+
+// lgamma is the log of the absolute value of gamma.
+func lgamma(x float64) float64 {
+	r, _ := math.Lgamma(x)
+	return r
+}
+
+// beta is the beta function B(a, b), which is
+// gamma(a) gamma(b) / gamma(a+b).
+func beta(a, b float64) float64 {
+	g, s1 := math.Lgamma(a)
+	h, s2 := math.Lgamma(b)
+	k, s3 := math.Lgamma(a + b)
+
+	return float64(s1*s2*s3) * math.Exp(g+h-k)
+}
+
+// gammaInc is the regularized lower incomplete gamma
+// function P(a, x), for a > 0 and x >= 0.
+func gammaInc(a, x float64) float64 {
+	switch {
+	case a <= 0 || x < 0 || math.IsNaN(x):
+		return math.NaN()
+	case x == 0:
+		return 0
+	case math.IsInf(x, 1):
+		return 1
+	case x < a+1:
+		return gammaSeries(a, x)
+	}
+
+	return 1 - gammaFraction(a, x)
+}
+
+// gammaIncComp is the regularized upper incomplete
+// gamma function Q(a, x), which is 1 - P(a, x).
+func gammaIncComp(a, x float64) float64 {
+	switch {
+	case a <= 0 || x < 0 || math.IsNaN(x):
+		return math.NaN()
+	case x == 0:
+		return 1
+	case math.IsInf(x, 1):
+		return 0
+	case x < a+1:
+		return 1 - gammaSeries(a, x)
+	}
+
+	return gammaFraction(a, x)
+}
+
+// gammaSeries finds P(a, x) by its series,
+// which converges quickly for x < a+1.
+func gammaSeries(a, x float64) float64 {
+	t := 1 / a
+	s := t
+
+	for n, l := 1, gammaIter(a); n < l; n++ {
+		t *= x / (a + float64(n))
+		s += t
+
+		if math.Abs(t) < math.Abs(s)*specialEps {
+			break
+		}
+	}
+
+	return s * math.Exp(-x+a*math.Log(x)-lgamma(a))
+}
+
+// gammaFraction finds Q(a, x) by its continued
+// fraction, which converges quickly for x >= a+1.
+func gammaFraction(a, x float64) float64 {
+	b := x + 1 - a
+	c := 1 / specialTiny
+	d := 1 / b
+	h := d
+
+	for n, l := 1, gammaIter(a); n < l; n++ {
+		k := -float64(n) * (float64(n) - a)
+		b += 2
+
+		if d = k*d + b; math.Abs(d) < specialTiny {
+			d = specialTiny
+		}
+
+		if c = b + k/c; math.Abs(c) < specialTiny {
+			c = specialTiny
+		}
+
+		d = 1 / d
+		e := d * c
+		h *= e
+
+		if math.Abs(e-1) < specialEps {
+			break
+		}
+	}
+
+	return h * math.Exp(-x+a*math.Log(x)-lgamma(a))
+}
+
+// betaInc is the regularized incomplete beta function
+// I(x; a, b), for a, b > 0 and 0 <= x <= 1.
+func betaInc(a, b, x float64) float64 {
+	switch {
+	case a <= 0 || b <= 0 || x < 0 || x > 1 || math.IsNaN(x):
+		return math.NaN()
+	case x == 0:
+		return 0
+	case x == 1:
+		return 1
+	}
+
+	f := math.Exp(lgamma(a+b) - lgamma(a) - lgamma(b) + a*math.Log(x) + b*math.Log1p(-x))
+
+	// the continued fraction converges quickly only on
+	// one side, so we use the symmetry I(x; a, b) = 1 -
+	// I(1-x; b, a) on the other
+
+	if x < (a+1)/(a+b+2) {
+		return f * betaFraction(a, b, x) / a
+	}
+
+	return 1 - f*betaFraction(b, a, 1-x)/b
+}
+
+// betaFraction is the continued fraction for betaInc.
+func betaFraction(a, b, x float64) float64 {
+	c := 1.0
+	d := 1 - (a+b)*x/(a+1)
+
+	if math.Abs(d) < specialTiny {
+		d = specialTiny
+	}
+
+	d = 1 / d
+	h := d
+
+	step := func(k float64) {
+		if d = 1 + k*d; math.Abs(d) < specialTiny {
+			d = specialTiny
+		}
+
+		if c = 1 + k/c; math.Abs(c) < specialTiny {
+			c = specialTiny
+		}
+
+		d = 1 / d
+		h *= d * c
+	}
+
+	for n := 1; n < specialIter; n++ {
+		f := float64(n)
+		a2 := a + 2*f
+
+		step(f * (b - f) * x / ((a2 - 1) * a2))
+
+		k := -(a + f) * (a + b + f) * x / (a2 * (a2 + 1))
+		h0 := h
+
+		step(k)
+
+		if math.Abs(h/h0-1) < specialEps {
+			break
+		}
+	}
+
+	return h
+}
@@ -3,6 +3,7 @@ package oak
 import (
 	"fmt"
 	"io"
+	"math/big"
 	"strconv"
 	"strings"
 )
@@ -12,6 +13,7 @@ type (
 	mode    uint
 	radix   uint
 	display uint
+	numeric uint
 	sreg    uint
 )
 
@@ -21,6 +23,14 @@ const (
 	stringer
 	symbol
 	word
+	vector
+	matrix
+	complexer
+	bigint
+	rational
+	bigfloat
+	datetime
+	quantity
 )
 
 const (
@@ -33,6 +43,7 @@ const (
 	base02
 	base08
 	base16
+	baseN // any other base, from 2 to 36
 )
 
 const (
@@ -40,6 +51,15 @@ const (
 	fixed
 	scientific
 	engineering
+	ratio
+	mixed
+)
+
+const (
+	floats numeric = iota
+	bigints
+	rationals
+	precise
 )
 
 const (
@@ -61,15 +81,29 @@ type Machine struct {
 	stack   []*Value
 	x       *Value
 	stats   []*Value
+	tvm     []*Value
+	flows   []cashFlow
+	data    []dataPoint
 	vars    map[string]*Symbol
 	words   map[string]*Word
 	builtin map[string]Expr
 	output  io.Writer
+	loops   []float64
+	marks   []int
 	autos   string
+	depth   int
+	iters   int
 	digits  uint
+	maxLoop uint
+	prec    uint
+	ints    intState
 	disp    display
 	base    radix
+	nbase   int
 	mode    mode
+	num     numeric
+	cplx    bool
+	begin   bool
 	debug   bool
 	inter   bool
 }
@@ -142,12 +176,15 @@ func (m *Machine) AutoSave() {
 // operations the stack exports on itself don't return
 // errors, only values (possibly nil, stack unchanged)
 func (m *Machine) Eval(line int, exprs []Expr) (interface{}, error) {
+	m.iters = 0
+
 	for _, e := range exprs {
 		if e == nil {
 			return nil, fmt.Errorf("found nil expression")
 		}
 
 		if err := e.Eval(m); err != nil {
+			m.marks = nil // drop any unfinished vector or matrix
 			return nil, err
 		}
 
@@ -177,6 +214,8 @@ func (m *Machine) Base() int {
 		return 8
 	case base16:
 		return 16
+	case baseN:
+		return m.nbase
 	}
 
 	return 10
@@ -191,6 +230,21 @@ func (m *Machine) Mode() string {
 	return rad
 }
 
+// Numeric returns the number mode, if other than
+// ordinary floats (i.e., the mode for exact results).
+func (m *Machine) Numeric() string {
+	switch m.num {
+	case bigints:
+		return "big"
+	case rationals:
+		return "exact"
+	case precise:
+		return fmt.Sprintf("precise/%d", m.precision())
+	}
+
+	return ""
+}
+
 // Display returns the display mode and precision (digits).
 func (m *Machine) Display() string {
 	switch m.disp {
@@ -200,6 +254,10 @@ func (m *Machine) Display() string {
 		return fmt.Sprintf("sci/%d", m.digits)
 	case engineering:
 		return fmt.Sprintf("eng/%d", m.digits)
+	case ratio:
+		return "ratio"
+	case mixed:
+		return "mixed"
 	}
 
 	return "free"
@@ -222,10 +280,38 @@ func (m *Machine) SetOptions(home string, opts map[string]string) {
 		}
 	}
 
+	if number, ok := opts["number_mode"]; ok {
+		for _, s := range strings.Split(number, ",") {
+			m.setMode(strings.TrimSpace(s))
+		}
+	}
+
+	if size, ok := opts["word_size"]; ok {
+		if n, err := strconv.Atoi(size); err == nil && n >= 1 && n <= 64 {
+			m.ints.size = uint(n)
+		}
+	}
+
+	if comp, ok := opts["complement"]; ok {
+		m.setMode(comp)
+	}
+
+	if prec, ok := opts["precision"]; ok {
+		if n, err := strconv.Atoi(prec); err == nil && n >= 2 && n <= maxPrec {
+			m.prec = uint(n)
+		}
+	}
+
 	if display, ok := opts["display_mode"]; ok {
 		m.setDisplay(display)
 	}
 
+	if loops, ok := opts["max_loops"]; ok {
+		if n, err := strconv.Atoi(loops); err == nil && n >= 0 {
+			m.maxLoop = uint(n)
+		}
+	}
+
 	if auto, ok := opts["autosave"]; ok {
 		if strings.ToLower(auto) == "true" {
 			m.autos = home
@@ -234,7 +320,9 @@ func (m *Machine) SetOptions(home string, opts map[string]string) {
 }
 
 func (m *Machine) initStats() {
-	zero := m.makeFloatVal(0.0)
+	// the registers are floats, whatever the base
+
+	zero := Value{T: floater, M: m.mode, V: 0.0, m: m}
 	m.stats = make([]*Value, nsreg)
 
 	for i := 0; i < int(nsreg); i++ {
@@ -248,6 +336,7 @@ func (m *Machine) initStats() {
 
 func (m *Machine) clearStats() {
 	m.stats = nil
+	m.data = nil
 
 	for i := 0; i < int(nsreg); i++ {
 		delete(m.vars, fmt.Sprintf("r_%d", i+2))
@@ -262,15 +351,49 @@ func (m *Machine) makeFloatVal(s float64) Value {
 		v = s
 		t = floater
 	} else {
-		v = uint(s)
+		v = m.ints.fromFloat(s)
 		t = integer
 	}
 
 	return Value{t, m.mode, v, m}
 }
 
+// makeFlagVal returns the canonical true (1)
+// or false (0) value in the current base.
+func (m *Machine) makeFlagVal(b bool) Value {
+	if b {
+		return m.makeFloatVal(1)
+	}
+
+	return m.makeFloatVal(0)
+}
+
 func (m *Machine) makeIntVal(i uint) Value {
-	return Value{T: integer, M: m.mode, V: i, m: m}
+	return Value{T: integer, M: m.mode, V: i & m.ints.mask(), m: m}
+}
+
+func (m *Machine) makeVectorVal(v []float64) Value {
+	return Value{T: vector, M: m.mode, V: v, m: m}
+}
+
+func (m *Machine) makeMatrixVal(a [][]float64) Value {
+	return Value{T: matrix, M: m.mode, V: a, m: m}
+}
+
+func (m *Machine) makeComplexVal(z complex128) Value {
+	return Value{T: complexer, M: m.mode, V: z, m: m}
+}
+
+func (m *Machine) makeBigVal(n *big.Int) Value {
+	return Value{T: bigint, M: m.mode, V: n, m: m}
+}
+
+func (m *Machine) makeRatVal(r *big.Rat) Value {
+	return Value{T: rational, M: m.mode, V: r, m: m}
+}
+
+func (m *Machine) makeBigFloatVal(f *big.Float) Value {
+	return Value{T: bigfloat, M: m.mode, V: f, m: m}
 }
 
 func (m *Machine) makeStringVal(s string) Value {
@@ -291,24 +414,60 @@ func (m *Machine) setMode(s string) {
 		m.mode = degrees
 	case rad:
 		m.mode = radians
+	case "complex":
+		m.cplx = true
+	case "real":
+		m.cplx = false
+	case "big":
+		m.num = bigints
+	case "exact":
+		m.num = rationals
+	case "precise":
+		m.num = precise
+	case "float":
+		m.num = floats
+	case "unsigned":
+		m.ints.comp = unsigned
+	case "1s":
+		m.ints.comp = onesComp
+	case "2s":
+		m.ints.comp = twosComp
+	case "begin":
+		m.begin = true
+	case "end":
+		m.begin = false
 	}
 }
 
 func (m *Machine) setBase(s string) {
 	if base, err := strconv.Atoi(s); err == nil {
-		switch base {
-		case 2:
-			m.base = base02
-		case 8:
-			m.base = base08
-		case 16:
-			m.base = base16
-		default:
+		if !m.setRadix(base) {
 			m.base = base10
 		}
 	}
 }
 
+// setRadix changes to any base from 2 to 36, or
+// returns false if the base isn't valid.
+func (m *Machine) setRadix(base int) bool {
+	switch {
+	case base == 2:
+		m.base = base02
+	case base == 8:
+		m.base = base08
+	case base == 10:
+		m.base = base10
+	case base == 16:
+		m.base = base16
+	case base >= 2 && base <= 36:
+		m.base, m.nbase = baseN, base
+	default:
+		return false
+	}
+
+	return true
+}
+
 func (m *Machine) setDisplay(s string) {
 	switch s {
 	case "fix":
@@ -317,6 +476,10 @@ func (m *Machine) setDisplay(s string) {
 		m.disp = scientific
 	case "eng":
 		m.disp = engineering
+	case "ratio":
+		m.disp = ratio
+	case "mixed":
+		m.disp = mixed
 	default:
 		m.disp = free
 	}
@@ -1,6 +1,7 @@
 package oak
 
 import (
+	"strings"
 	"unicode"
 	"unicode/utf8"
 
@@ -73,6 +74,12 @@ func lexAny(l *Scanner) stateFn { // nolint:gocyclo
 	case r == '"':
 		return lexQuote
 
+	case r == '.' && !l.isNumeral(l.peek()):
+		// A period by itself (not part of a
+		// number) is the print operator.
+		l.emit(token.Operator)
+		return lexAny
+
 	case r == '-':
 		// It's an operator if it's preceded immediately (no spaces) by an operand, which is
 		// an identifier, an indexed expression, or a parenthesized expression.
@@ -147,9 +154,9 @@ func lexSpace(l *Scanner) stateFn {
 	return lexAny
 }
 
-// lexIdentifier scans an alphanumeric.
-// If the input base is greater than 10, some identifiers
-// are actually numbers. We handle this here.
+// lexIdentifier scans an alphanumeric. A number always starts
+// with a decimal digit, even if the input base is greater than
+// 10, so an identifier (e.g., dec in base 16) isn't a number.
 func lexIdentifier(l *Scanner) stateFn {
 loop:
 	for {
@@ -160,19 +167,26 @@ loop:
 		default:
 			l.backup()
 
+			// an identifier may end with ? (e.g., b? to test a bit),
+			// and hms may end with + or - (for hms+ and hms-)
+
+			if r == '?' || (r == '+' || r == '-') && isSigned(l.input[l.start:l.pos]) {
+				l.next()
+			}
+
 			if !l.atTerminator() {
 				return l.errorf("bad character %#U", r)
 			}
 
-			word := l.input[l.start:l.pos]
+			// we must follow a change of base in the middle
+			// of a line, as the parser does
 
-			switch {
-			case isAllDigits(word, l.config.Base):
-				l.emit(token.Number)
-			default:
-				l.emit(token.Identifier)
+			if base, ok := baseChange(l.last, l.input[l.start:l.pos]); ok {
+				l.base = base
 			}
 
+			l.emit(token.Identifier)
+
 			break loop
 		}
 	}
@@ -249,29 +263,47 @@ func lexNumber(l *Scanner) stateFn {
 		return l.errorf("bad number syntax: %s", l.input[l.start:l.pos])
 	}
 
+	l.scanUnit()
+
 	l.emit(token.Number)
 	return lexAny
 }
 
 func (l *Scanner) scanNumber() bool {
-	base := l.config.Base
-	digits := digitsForBase(base)
-
-	// If base 0, acccept binary for 0b / 0B or hex for 0x / 0X.
-	// fmt.Fprintf(os.Stderr, "base=%d\n", base)
-
-	if base == 0 {
-		if l.accept("0") {
-			if l.accept("xX") {
-				digits = digitsForBase(16)
-			} else if l.accept("bB") {
-				digits = "01"
+	digits := digitsForBase(l.base)
+
+	// a date (e.g., 2026-10-17) may have a time (T13:45:30)
+
+	if dateLiteral.MatchString(l.input[l.pos:]) {
+		l.pos += len("2006-01-02")
+
+		if l.accept("T") {
+			l.acceptRun("0123456789:.")
+		}
+
+		return l.atTerminator()
+	}
+
+	// Accept binary for 0b / 0B, hex for 0x / 0X, or any base
+	// for 0r / 0R with the base and a colon (e.g., 0r36:zz).
+
+	if l.accept("0") {
+		if l.accept("xX") {
+			digits = digitsForBase(16)
+		} else if l.accept("bB") {
+			digits = "01"
+		} else if l.accept("rR") {
+			l.acceptRun(decimal)
+
+			if !l.accept(":") {
+				return false
 			}
+
+			digits = digitsForBase(36)
 		}
-		// Otherwise leave it decimal (0); strconv.ParseInt will take care of it.
+		// Otherwise leave it in the input base; strconv.ParseInt will take care of it.
 		// We can't set it to 8 in case it's a leading-0 float like 0.69 or 09e4.
 	}
-	// fmt.Fprintf(os.Stderr, "digits=%s\n", digits)
 
 	l.acceptRun(digits)
 
@@ -279,6 +311,13 @@ func (l *Scanner) scanNumber() bool {
 		l.acceptRun(digits)
 	}
 
+	// an angle may have minutes and seconds (e.g., 12°30'15")
+
+	if l.accept("°") {
+		l.acceptRun("0123456789.'\"")
+		return l.atTerminator()
+	}
+
 	if l.accept("eE") {
 		l.accept("+-")
 		l.acceptRun("0123456789")
@@ -300,17 +339,17 @@ func (l *Scanner) scanNumber() bool {
 	return true
 }
 
-var digits [16 + 1]string
+var digits [36 + 1]string
 
 const (
 	decimal = "0123456789"
-	lower   = "abcdef"
-	upper   = "ABCDEF"
+	lower   = "abcdefghijklmnopqrstuvwxyz"
+	upper   = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
 )
 
 // digitsForBase returns the digit set for numbers in the specified base.
 func digitsForBase(base int) string {
-	if base == 0 {
+	if base < 2 || base > 36 {
 		base = 10
 	}
 
@@ -355,3 +394,34 @@ loop:
 	l.emit(token.String)
 	return lexAny
 }
+
+// scanUnit scans a unit following a number and a space (e.g.,
+// 9.81 m/s^2) as part of the number, if it's a valid unit.
+func (l *Scanner) scanUnit() {
+	pos := l.pos
+
+	if !l.accept(" ") {
+		return
+	}
+
+	for isSpace(l.peek()) {
+		l.next()
+	}
+
+	start := l.pos
+
+	// a unit may be a reciprocal (e.g., 1/s)
+
+	if !unicode.IsLetter(l.peek()) && !strings.HasPrefix(l.input[l.pos:], "1/") {
+		l.pos = pos
+		return
+	}
+
+	for isUnitRune(l.peek()) {
+		l.next()
+	}
+
+	if _, err := parseUnit(l.input[start:l.pos]); err != nil || l.pos == start || !l.atTerminator() {
+		l.pos = pos
+	}
+}
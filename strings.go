@@ -0,0 +1,238 @@
+package oak
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	Length ExprFunc = func(m *Machine) error {
+		s, err := m.popString("len")
+
+		if err != nil {
+			return err
+		}
+
+		m.Push(m.makeFloatVal(float64(utf8.RuneCountInString(s))))
+		return nil
+	}
+
+	// Substring pops a count {x}, a starting index {y}, and a
+	// string {z}, and pushes the characters of the string from
+	// the index (starting at 0).
+	Substring ExprFunc = func(m *Machine) error {
+		l := len(m.stack)
+
+		if l < 3 {
+			return errUnderflow
+		}
+
+		// we check everything before popping anything,
+		// so the stack is left alone on an error
+
+		x, y, z := m.stack[l-1], m.stack[l-2], m.stack[l-3]
+
+		if z.T != stringer {
+			return fmt.Errorf("substr: invalid operand z=%#v", z.V)
+		}
+
+		s := z.V.(string)
+		r := []rune(s)
+
+		if _, ok1 := x.float(); !ok1 {
+			return fmt.Errorf("substr: invalid operands y=%#v, x=%#v", y.V, x.V)
+		}
+
+		if _, ok2 := y.float(); !ok2 {
+			return fmt.Errorf("substr: invalid operands y=%#v, x=%#v", y.V, x.V)
+		}
+
+		i, ok1 := y.whole(len(r))
+		n, ok2 := x.whole(len(r) - i)
+
+		if !ok1 || !ok2 {
+			return fmt.Errorf("substr: out of range %v:%v in %q", y.V, x.V, s)
+		}
+
+		m.PopX()
+		m.Pop()
+		m.Pop()
+
+		m.Push(m.makeStringVal(string(r[i : i+n])))
+		return nil
+	}
+
+	Upper = StringOp("upper", strings.ToUpper)
+	Lower = StringOp("lower", strings.ToLower)
+
+	// Find pops a string {x} to search for in another
+	// string {y}, and pushes its index, or -1 if missing.
+	Find ExprFunc = func(m *Machine) error {
+		t, err := m.popString("find")
+
+		if err != nil {
+			return err
+		}
+
+		s, err := m.popString("find")
+
+		if err != nil {
+			return err
+		}
+
+		i := strings.Index(s, t)
+
+		if i > 0 {
+			i = utf8.RuneCountInString(s[:i])
+		}
+
+		m.Push(m.makeFloatVal(float64(i)))
+		return nil
+	}
+
+	// Split pops a separator {x} and a string {y}, and pushes
+	// each part of the string followed by the number of parts.
+	Split ExprFunc = func(m *Machine) error {
+		sep, err := m.popString("split")
+
+		if err != nil {
+			return err
+		}
+
+		s, err := m.popString("split")
+
+		if err != nil {
+			return err
+		}
+
+		parts := strings.Split(s, sep)
+
+		for _, p := range parts {
+			m.Push(m.makeStringVal(p))
+		}
+
+		m.Push(m.makeFloatVal(float64(len(parts))))
+		return nil
+	}
+
+	// Join pops a separator {x} and a count {y}, and then that
+	// many strings, and pushes them joined into one string.
+	Join ExprFunc = func(m *Machine) error {
+		l := len(m.stack)
+
+		if l < 2 {
+			return errUnderflow
+		}
+
+		// we check everything before popping anything,
+		// so the stack is left alone on an error
+
+		x, y := m.stack[l-1], m.stack[l-2]
+
+		if x.T != stringer {
+			return fmt.Errorf("join: invalid operand x=%#v", x.V)
+		}
+
+		if _, ok := y.float(); !ok {
+			return fmt.Errorf("join: invalid count %#v", y.V)
+		}
+
+		n, ok := y.whole(l - 2)
+
+		if !ok {
+			if f, _ := y.float(); f > float64(l-2) {
+				return errUnderflow
+			}
+
+			return fmt.Errorf("join: invalid count %#v", y.V)
+		}
+
+		parts := make([]string, n)
+
+		for i := range parts {
+			s := m.stack[l-2-n+i]
+
+			if s.T != stringer {
+				return fmt.Errorf("join: invalid operand %#v", s.V)
+			}
+
+			parts[i] = s.V.(string)
+		}
+
+		m.PopX()
+		m.stack = m.stack[:l-2-n]
+
+		m.Push(m.makeStringVal(strings.Join(parts, x.V.(string))))
+		return nil
+	}
+
+	// ToString replaces the top of stack with a string
+	// showing its value in the current display mode.
+	ToString ExprFunc = func(m *Machine) error {
+		if len(m.stack) < 1 {
+			return errUnderflow
+		}
+
+		x := m.PopX()
+
+		m.Push(m.makeStringVal(x.String()))
+		return nil
+	}
+
+	// ToNumber replaces a string on top of stack with
+	// the number it represents in the current base.
+	ToNumber ExprFunc = func(m *Machine) error {
+		s, err := m.popString("num")
+
+		if err != nil {
+			return err
+		}
+
+		e, err := parseNumber(strings.TrimSpace(s), m.Base())
+
+		if err != nil {
+			return fmt.Errorf("num: invalid number %q", s)
+		}
+
+		return e.Eval(m)
+	}
+)
+
+// StringOp returns an expression which replaces a string
+// on top of the stack with the result of the function.
+func StringOp(op string, f func(string) string) ExprFunc {
+	return func(m *Machine) error {
+		s, err := m.popString(op)
+
+		if err != nil {
+			return err
+		}
+
+		m.Push(m.makeStringVal(f(s)))
+		return nil
+	}
+}
+
+// popString removes a string from the top of stack.
+func (m *Machine) popString(op string) (string, error) {
+	if len(m.stack) < 1 {
+		return "", errUnderflow
+	}
+
+	if t := m.Top(); t.T != stringer {
+		return "", fmt.Errorf("%s: invalid operand x=%#v", op, t.V)
+	}
+
+	return m.PopX().V.(string), nil
+}
+
+// binaryString applies an operation to strings; only
+// addition (concatenation) makes sense.
+func (m *Machine) binaryString(op string, y, x *Value) (Value, error) {
+	if op != "add" || x.T != stringer || y.T != stringer {
+		return Value{}, fmt.Errorf("%s: mismatched operands y=%#v, x=%#v", op, y.V, x.V)
+	}
+
+	return m.makeStringVal(y.V.(string) + x.V.(string)), nil
+}
@@ -109,7 +109,7 @@ var (
 		v := m.Pop()
 
 		switch v.T {
-		case integer, floater, stringer:
+		case integer, floater, stringer, vector, matrix, complexer, bigint, rational, bigfloat:
 		default:
 			return fmt.Errorf("store: invalid value %#v", v.V)
 		}
@@ -0,0 +1,619 @@
+package oak
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// dims are the powers of the SI base units
+// m, kg, s, A, K, mol, and cd in a unit.
+type dims [7]int
+
+// maxPower is the largest power of a named unit in a unit
+// (e.g., m^9), either way.
+const maxPower = 9
+
+// baseUnit is a named unit with its size in SI units.
+type baseUnit struct {
+	f float64
+	d dims
+}
+
+var (
+	dimLength   = dims{1}
+	dimMass     = dims{0, 1}
+	dimDuration = dims{0, 0, 1}
+	dimCurrent  = dims{0, 0, 0, 1}
+	dimTemp     = dims{0, 0, 0, 0, 1}
+	dimAmount   = dims{0, 0, 0, 0, 0, 1}
+	dimLight    = dims{0, 0, 0, 0, 0, 0, 1}
+	dimArea     = dims{2}
+	dimVolume   = dims{3}
+	dimSpeed    = dims{1, 0, -1}
+	dimFreq     = dims{0, 0, -1}
+	dimForce    = dims{1, 1, -2}
+	dimPressure = dims{-1, 1, -2}
+	dimEnergy   = dims{2, 1, -2}
+	dimPower    = dims{2, 1, -3}
+	dimCharge   = dims{0, 0, 1, 1}
+	dimVoltage  = dims{2, 1, -3, -1}
+	dimOhms     = dims{2, 1, -3, -2}
+)
+
+// units are the known units by name; there are no prefixes
+// other than those listed, and no units with the same name
+// as an operation (e.g., min for minutes, or rad).
+var units = map[string]baseUnit{
+	"m":   {1, dimLength},
+	"km":  {1e3, dimLength},
+	"cm":  {1e-2, dimLength},
+	"mm":  {1e-3, dimLength},
+	"um":  {1e-6, dimLength},
+	"µm":  {1e-6, dimLength},
+	"nm":  {1e-9, dimLength},
+	"in":  {0.0254, dimLength},
+	"ft":  {0.3048, dimLength},
+	"yd":  {0.9144, dimLength},
+	"mi":  {1609.344, dimLength},
+	"nmi": {1852, dimLength},
+	"au":  {149597870700, dimLength},
+	"ly":  {9460730472580800, dimLength},
+
+	"kg": {1, dimMass},
+	"g":  {1e-3, dimMass},
+	"mg": {1e-6, dimMass},
+	"t":  {1e3, dimMass},
+	"lb": {0.45359237, dimMass},
+	"oz": {0.028349523125, dimMass},
+
+	"s":  {1, dimDuration},
+	"ms": {1e-3, dimDuration},
+	"us": {1e-6, dimDuration},
+	"µs": {1e-6, dimDuration},
+	"ns": {1e-9, dimDuration},
+	"h":  {3600, dimDuration},
+	"d":  {86400, dimDuration},
+	"wk": {604800, dimDuration},
+	"yr": {31557600, dimDuration}, // Julian year
+
+	"A":   {1, dimCurrent},
+	"mA":  {1e-3, dimCurrent},
+	"K":   {1, dimTemp},
+	"mol": {1, dimAmount},
+	"cd":  {1, dimLight},
+
+	"ha":   {1e4, dimArea},
+	"acre": {4046.8564224, dimArea},
+	"L":    {1e-3, dimVolume},
+	"mL":   {1e-6, dimVolume},
+	"gal":  {3.785411784e-3, dimVolume},
+
+	"mph": {0.44704, dimSpeed},
+	"kn":  {1852.0 / 3600, dimSpeed},
+
+	"Hz":  {1, dimFreq},
+	"kHz": {1e3, dimFreq},
+	"MHz": {1e6, dimFreq},
+	"GHz": {1e9, dimFreq},
+
+	"N":   {1, dimForce},
+	"kN":  {1e3, dimForce},
+	"lbf": {4.4482216152605, dimForce},
+
+	"Pa":  {1, dimPressure},
+	"kPa": {1e3, dimPressure},
+	"bar": {1e5, dimPressure},
+	"atm": {101325, dimPressure},
+	"psi": {6894.757293168361, dimPressure},
+
+	"J":    {1, dimEnergy},
+	"kJ":   {1e3, dimEnergy},
+	"cal":  {4.184, dimEnergy},
+	"kcal": {4184, dimEnergy},
+	"Wh":   {3600, dimEnergy},
+	"kWh":  {3.6e6, dimEnergy},
+	"eV":   {1.602176634e-19, dimEnergy},
+	"BTU":  {1055.05585262, dimEnergy},
+
+	"W":  {1, dimPower},
+	"kW": {1e3, dimPower},
+	"MW": {1e6, dimPower},
+	"hp": {745.69987158227022, dimPower},
+
+	"C":   {1, dimCharge},
+	"V":   {1, dimVoltage},
+	"mV":  {1e-3, dimVoltage},
+	"kV":  {1e3, dimVoltage},
+	"ohm": {1, dimOhms},
+	"Ω":   {1, dimOhms},
+}
+
+// factor is a named unit raised to a power.
+type factor struct {
+	S string
+	P int
+}
+
+// unit is a product of factors, e.g., m/s^2 is m*s^-2.
+type unit []factor
+
+// measure is a number with a unit.
+type measure struct {
+	V float64 `json:"value"`
+	U string  `json:"unit"`
+}
+
+var (
+	// ConvertUnit pops a unit (a string) and converts the
+	// top of stack to that unit; a number without a unit
+	// is given the unit.
+	ConvertUnit ExprFunc = func(m *Machine) error {
+		if len(m.stack) < 2 {
+			return errUnderflow
+		}
+
+		x := m.Pop()
+
+		if x.T != stringer {
+			return fmt.Errorf("to: invalid operand x=%#v", x.V)
+		}
+
+		u, err := parseUnit(x.V.(string))
+
+		if err != nil {
+			return fmt.Errorf("to: %s %q", err, x.V)
+		}
+
+		y := m.PopX()
+		r, err := m.convertUnit(y, u)
+
+		if err != nil {
+			return fmt.Errorf("to: %s", err)
+		}
+
+		m.Push(r)
+		return nil
+	}
+
+	// Magnitude replaces a number with a unit
+	// by the number alone (in that unit).
+	Magnitude ExprFunc = func(m *Machine) error {
+		if len(m.stack) < 1 {
+			return errUnderflow
+		}
+
+		x := m.PopX()
+
+		switch x.T {
+		case quantity:
+			m.Push(m.makeFloatVal(x.V.(measure).V))
+			return nil
+
+		case floater, integer, bigint, rational, bigfloat:
+			m.Push(*x)
+			return nil
+		}
+
+		return fmt.Errorf("mag: invalid operand x=%#v", x.V)
+	}
+)
+
+// UnitLiteral pushes a number with a unit (e.g., 3 ft).
+func UnitLiteral(u unit, other Expr) ExprFunc {
+	return func(m *Machine) error {
+		if err := other.Eval(m); err != nil {
+			return err
+		}
+
+		x := m.Pop()
+		f, ok := x.float()
+
+		if !ok {
+			return fmt.Errorf("invalid operand x=%#v", x.V)
+		}
+
+		m.Push(m.makeUnitVal(f, u))
+		return nil
+	}
+}
+
+// makeUnitVal returns a number with a unit, or just the
+// number if the unit has no dimensions (e.g., Hz*s or ft/m,
+// which are scaled to a plain number).
+func (m *Machine) makeUnitVal(f float64, u unit) Value {
+	if s, d := u.si(); d == (dims{}) {
+		return m.makeFloatVal(f * s)
+	}
+
+	return Value{T: quantity, M: m.mode, V: measure{f, u.String()}, m: m}
+}
+
+// splitUnit separates a number from its unit, if any.
+func splitUnit(s string) (string, string) {
+	if i := strings.IndexByte(s, ' '); i > 0 {
+		return s[:i], strings.TrimSpace(s[i:])
+	}
+
+	return s, ""
+}
+
+// isUnitRune reports whether a character
+// may be part of a unit (e.g., m/s^2).
+func isUnitRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("/*^-", r)
+}
+
+// parseUnit reads a unit made of named units with optional
+// (integer) powers, multiplied with * or divided with /; the
+// empty string (or 1) is a unit without any dimensions.
+func parseUnit(s string) (unit, error) {
+	var u unit
+
+	if s == "" || s == "1" {
+		return u, nil
+	}
+
+	sign := 1
+
+	for len(s) > 0 {
+		i := strings.IndexAny(s, "*/")
+
+		if i < 0 {
+			i = len(s)
+		}
+
+		name, p := s[:i], 1
+
+		if j := strings.IndexByte(name, '^'); j >= 0 {
+			n, err := strconv.Atoi(name[j+1:])
+
+			if err != nil || n == 0 || n < -maxPower || n > maxPower {
+				return nil, fmt.Errorf("invalid power")
+			}
+
+			name, p = name[:j], n
+		}
+
+		// we allow (e.g.) 1/s for a frequency
+
+		if name != "1" || p != 1 || len(u) > 0 {
+			if _, ok := units[name]; !ok {
+				return nil, fmt.Errorf("unknown unit")
+			}
+
+			u = u.times(unit{{name, sign * p}})
+		}
+
+		if i == len(s) {
+			break
+		}
+
+		if s[i] == '/' {
+			sign = -1
+		} else {
+			sign = 1
+		}
+
+		if s = s[i+1:]; s == "" {
+			return nil, fmt.Errorf("invalid unit")
+		}
+	}
+
+	if len(u) == 0 {
+		return nil, fmt.Errorf("invalid unit")
+	}
+
+	if !u.valid() {
+		return nil, fmt.Errorf("invalid power")
+	}
+
+	return u, nil
+}
+
+// valid reports whether the powers in a unit are in range.
+func (u unit) valid() bool {
+	for _, f := range u {
+		if f.P < -maxPower || f.P > maxPower {
+			return false
+		}
+	}
+
+	return true
+}
+
+// name shows a unit for an error message, where
+// a unit without dimensions isn't just 1.
+func (u unit) name() string {
+	if len(u) == 0 {
+		return "dimensionless"
+	}
+
+	return u.String()
+}
+
+// String shows a unit with any negative powers after
+// a slash, e.g., kg*m/s^2, or W/m^2/K.
+func (u unit) String() string {
+	var b strings.Builder
+
+	for _, f := range u {
+		if f.P < 0 {
+			continue
+		}
+
+		if b.Len() > 0 {
+			b.WriteByte('*')
+		}
+
+		f.write(&b)
+	}
+
+	if b.Len() == 0 {
+		b.WriteByte('1')
+	}
+
+	for _, f := range u {
+		if f.P > 0 {
+			continue
+		}
+
+		b.WriteByte('/')
+		f.P = -f.P
+		f.write(&b)
+	}
+
+	return b.String()
+}
+
+func (f factor) write(b *strings.Builder) {
+	b.WriteString(f.S)
+
+	if f.P != 1 {
+		fmt.Fprintf(b, "^%d", f.P)
+	}
+}
+
+// times multiplies two units, combining the
+// powers of factors with the same name.
+func (u unit) times(v unit) unit {
+	r := append(unit{}, u...)
+
+outer:
+	for _, f := range v {
+		for i := range r {
+			if r[i].S == f.S {
+				r[i].P += f.P
+				continue outer
+			}
+		}
+
+		r = append(r, f)
+	}
+
+	// remove any factors that cancelled out
+
+	n := 0
+
+	for _, f := range r {
+		if f.P != 0 {
+			r[n] = f
+			n++
+		}
+	}
+
+	return r[:n]
+}
+
+// pow raises a unit to an integer power.
+func (u unit) pow(n int) unit {
+	if n == 0 {
+		return nil
+	}
+
+	r := make(unit, len(u))
+
+	for i, f := range u {
+		r[i] = factor{f.S, f.P * n}
+	}
+
+	return r
+}
+
+// root takes the nth root of a unit, if
+// all its powers are multiples of n.
+func (u unit) root(n int) (unit, bool) {
+	r := make(unit, len(u))
+
+	for i, f := range u {
+		if f.P%n != 0 {
+			return nil, false
+		}
+
+		r[i] = factor{f.S, f.P / n}
+	}
+
+	return r, true
+}
+
+// si returns the size of a unit in SI units, and its dimensions.
+func (u unit) si() (float64, dims) {
+	var d dims
+
+	s := 1.0
+
+	for _, f := range u {
+		b := units[f.S]
+		s *= math.Pow(b.f, float64(f.P))
+
+		for i := range d {
+			d[i] += b.d[i] * f.P
+		}
+	}
+
+	return s, d
+}
+
+// unitOf returns a value as a number and its unit
+// (which is empty if it doesn't have one).
+func unitOf(v *Value) (float64, unit, bool) {
+	if v.T == quantity {
+		q := v.V.(measure)
+
+		// units are always valid, since they're
+		// checked when they're entered
+
+		u, _ := parseUnit(q.U)
+		return q.V, u, true
+	}
+
+	f, ok := v.float()
+	return f, nil, ok
+}
+
+// convert returns a number in unit u in the unit w,
+// if they have the same dimensions.
+func convert(f float64, u, w unit) (float64, error) {
+	a, d1 := u.si()
+	b, d2 := w.si()
+
+	if d1 != d2 {
+		return 0, fmt.Errorf("incompatible units %s vs %s", u.name(), w.name())
+	}
+
+	return f * a / b, nil
+}
+
+// convertUnit converts a value to another unit.
+func (m *Machine) convertUnit(v *Value, w unit) (Value, error) {
+	f, u, ok := unitOf(v)
+
+	if !ok {
+		return Value{}, fmt.Errorf("invalid operand y=%#v", v.V)
+	}
+
+	// a plain number just gets the unit
+
+	if v.T != quantity {
+		return m.makeUnitVal(f, w), nil
+	}
+
+	r, err := convert(f, u, w)
+
+	if err != nil {
+		return Value{}, err
+	}
+
+	return m.makeUnitVal(r, w), nil
+}
+
+// binaryUnit does arithmetic on numbers with units: they may
+// be multiplied, divided, or raised to an integer power, while
+// (e.g.) adding them requires the same dimensions, with the
+// result in the units of y; a plain number has no dimensions,
+// so it can't be added to a number with a unit.
+func (m *Machine) binaryUnit(op string, f func(float64, float64) float64, y, x *Value) (Value, error) {
+	a, u, ok1 := unitOf(y)
+	b, w, ok2 := unitOf(x)
+
+	if !ok1 || !ok2 {
+		return Value{}, fmt.Errorf("%s: mismatched operands y=%#v, x=%#v", op, y.V, x.V)
+	}
+
+	switch op {
+	case "mul":
+		return m.unitResult(op, a*b, u.times(w))
+
+	case "div":
+		return m.unitResult(op, a/b, u.times(w.pow(-1)))
+
+	case "pow":
+		if x.T == quantity || b != math.Trunc(b) || math.Abs(b) > maxPower {
+			return Value{}, fmt.Errorf("pow: invalid power of a unit x=%#v", x.V)
+		}
+
+		return m.unitResult(op, math.Pow(a, b), u.pow(int(b)))
+
+	case "add", "sub", "mod", "max", "min", "dist":
+		c, err := convert(b, w, u)
+
+		if err != nil {
+			return Value{}, fmt.Errorf("%s: %s", op, err)
+		}
+
+		return m.makeUnitVal(f(a, c), u), nil
+	}
+
+	return Value{}, fmt.Errorf("%s: invalid operands with units y=%#v, x=%#v", op, y.V, x.V)
+}
+
+// unitResult returns a number with a unit from arithmetic,
+// if the powers of the unit are still in range.
+func (m *Machine) unitResult(op string, f float64, u unit) (Value, error) {
+	if !u.valid() {
+		return Value{}, fmt.Errorf("%s: power of a unit out of range %s", op, u)
+	}
+
+	return m.makeUnitVal(f, u), nil
+}
+
+// unitFuncs are the functions which work on a number with
+// a unit, with the power they apply to the unit.
+var unitFuncs = map[string]struct{ n, d int }{
+	"abs":   {1, 1},
+	"ceil":  {1, 1},
+	"floor": {1, 1},
+	"trunc": {1, 1},
+	"sqr":   {2, 1},
+	"cube":  {3, 1},
+	"recp":  {-1, 1},
+	"sqrt":  {1, 2},
+	"cbrt":  {1, 3},
+}
+
+// unaryUnit applies a function to a number with a unit.
+func (m *Machine) unaryUnit(op string, f func(float64) float64, x *Value) (Value, error) {
+	q := x.V.(measure)
+	p, ok := unitFuncs[op]
+
+	if !ok {
+		return Value{}, fmt.Errorf("%s: invalid operand with units x=%#v", op, x.V)
+	}
+
+	u, _ := parseUnit(q.U)
+
+	if u, ok = u.pow(p.n).root(p.d); !ok {
+		return Value{}, fmt.Errorf("%s: invalid operand with units x=%#v", op, x.V)
+	}
+
+	return m.unitResult(op, f(q.V), u)
+}
+
+// compareUnit compares numbers with units of the same dimensions.
+func compareUnit(op string, y, x *Value) (int, error) {
+	a, u, ok1 := unitOf(y)
+	b, w, ok2 := unitOf(x)
+
+	if !ok1 || !ok2 {
+		return 0, fmt.Errorf("%s: mismatched operands y=%#v, x=%#v", op, y.V, x.V)
+	}
+
+	c, err := convert(b, w, u)
+
+	if err != nil {
+		return 0, fmt.Errorf("%s: %s", op, err)
+	}
+
+	switch {
+	case a < c:
+		return -1, nil
+	case a > c:
+		return 1, nil
+	case a != c:
+		return unordered, nil
+	}
+
+	return 0, nil
+}
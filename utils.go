@@ -1,6 +1,10 @@
 package oak
 
-import "unicode"
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
 
 // isSpace reports whether r is a space character.
 func isSpace(r rune) bool {
@@ -54,47 +58,35 @@ func (l *Scanner) isNumeral(r rune) bool {
 		return true
 	}
 
-	base := l.config.Base
-
-	if base < 10 {
-		return false
-	}
-
-	top := rune(base - 10)
-
-	if 'a' <= r && r <= 'a'+top {
-		return true
-	}
-
-	if 'A' <= r && r <= 'A'+top {
-		return true
-	}
-
-	return false
+	return strings.ContainsRune(digitsForBase(l.base), r)
 }
 
-// isAllDigits reports whether s consists of digits in the specified base.
-func isAllDigits(s string, base int) bool {
-	top := 'a' + rune(base-10) - 1
-	TOP := 'A' + rune(base-10) - 1
-
-	for _, c := range s {
-		if '0' <= c && c <= '9' {
-			continue
-		}
-
-		if 'a' <= c && c <= top {
-			continue
-		}
+// isSigned reports whether an identifier
+// may be followed by + or - (e.g., hms+).
+func isSigned(s string) bool {
+	return s == "hms"
+}
 
-		if 'A' <= c && c <= TOP {
-			continue
+// baseChange returns the new input base if the identifier
+// is a command that changes it; for base, that's the number
+// before it (if it's a literal, e.g., 36 base).
+func baseChange(prev, id string) (int, bool) {
+	switch id {
+	case "base":
+		if b, err := strconv.Atoi(prev); err == nil && b >= 2 && b <= 36 {
+			return b, true
 		}
-
-		return false
+	case "bin":
+		return 2, true
+	case "dec":
+		return 10, true
+	case "oct":
+		return 8, true
+	case "hex":
+		return 16, true
 	}
 
-	return true
+	return 0, false
 }
 
 // isOperator reports whether r is an operator. It may advance the lexer one character
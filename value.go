@@ -3,8 +3,10 @@ package oak
 import (
 	"fmt"
 	"math"
+	"math/big"
 	"math/bits"
 	"strconv"
+	"time"
 )
 
 // Value represents something that can be on the stack or
@@ -22,87 +24,7 @@ func (v Value) String() string { // nolint:gocyclo
 	switch v.T {
 	case floater:
 		// floats will always print as floats, not binary
-		switch v.m.disp {
-		case free:
-			// we don't need any special formatting
-			return fmt.Sprint(v.V.(float64))
-
-		case fixed:
-			return fmt.Sprintf("%.*f", v.m.digits, v.V.(float64))
-
-		case scientific:
-			return fmt.Sprintf("%.*e", v.m.digits, v.V.(float64))
-
-		case engineering:
-			// we have to calculate an exponent that's a multiple
-			// of three, and then scale the number to fit, and
-			// then make our own
-			f := v.V.(float64)
-			n := f < 0
-			d := v.m.digits
-			s := '+'
-
-			// we only use the log of a positive number
-			// so if the original value is negative,
-			// we'll change it here, and change back later
-
-			if n {
-				f = -f
-			}
-
-			e := int(math.Round(math.Log10(f)))
-
-			//fmt.Printf("before: f=%v, e=%v, n=%v, d=%v, s=%q\n", f, e, n, d, s)
-
-			// we need to find the correct multiple of 3
-			// which is weird when it's a fractional number
-
-			if f == 0.0 {
-				e = 0
-			} else if e >= 0 {
-				e = (e / 3) * 3
-			} else {
-				e = (-e + 3) / 3 * (-3)
-			}
-
-			// scale the number by the new exponent
-
-			f *= math.Pow10(-e)
-
-			// and now, fix the digits as needed because fix=2
-			// (0.00) with 10 becomes 10.0 with two significant
-			// digits after the mantissa
-
-			if f >= 1000 {
-				f /= 1000
-				e += 3
-			} else if f >= 100 && d > 1 {
-				d -= 2
-			} else if f >= 10 && d > 0 {
-				d--
-			}
-
-			// fix the sign of the exponent, since we're
-			// making it here, not using %e, etc.
-
-			if e < 0 {
-				s = '-'
-				e = -e
-			}
-
-			//fmt.Printf(" after: f=%v, e=%v, n=%v, d=%v, s=%q\n", f, e, n, d, s)
-
-			// fiddle the negative number back now
-
-			if n {
-				f = -f
-			}
-
-			// we use .*f so we can tell the format how many
-			// digits to use as the variable d
-
-			return fmt.Sprintf("%.*fe%c%02d", d, f, s, e)
-		}
+		return v.m.formatFloat(v.V.(float64))
 
 	case integer:
 		// we only have integers when a binary base is set (2, 8, 16)
@@ -111,21 +33,56 @@ func (v Value) String() string { // nolint:gocyclo
 		// we need to find out how many bits; we will then round
 		// that value based on the radix (2:8, 8:3, 16:2)
 		i := v.V.(uint)
+		w := v.m.ints.bits()
 
 		switch v.m.base {
 		case base02:
-			return fmt.Sprintf("%#0*b", places(i, 1, 8, 64), i)
+			return fmt.Sprintf("%#0*b", places(i, 1, wordPlaces(w, 1, 8), 64), i)
 		case base08:
-			return fmt.Sprintf("%#0*o", places(i, 3, 3, 24), i)
+			return fmt.Sprintf("%#0*o", places(i, 3, wordPlaces(w, 3, 3), 24), i)
 		case base16:
-			return fmt.Sprintf("%#0*x", places(i, 4, 4, 16), i)
+			return fmt.Sprintf("%#0*x", places(i, 4, wordPlaces(w, 4, 4), 16), i)
+		case baseN:
+			return fmt.Sprintf("0r%d:%s", v.m.nbase, strconv.FormatUint(uint64(i), v.m.nbase))
 		default:
-			return strconv.Itoa(int(v.V.(uint)))
+			// in decimal, an integer shows as signed (if
+			// that's the complement mode)
+
+			if v.m.ints.comp == unsigned {
+				return strconv.FormatUint(uint64(i), 10)
+			}
+
+			return strconv.FormatInt(v.m.ints.signed(i), 10)
 		}
 
 	case stringer:
 		return v.V.(string)
 
+	case vector:
+		return v.m.formatVector(v.V.([]float64))
+
+	case matrix:
+		return v.m.formatMatrix(v.V.([][]float64))
+
+	case complexer:
+		return v.m.formatComplex(v.V.(complex128))
+
+	case bigint:
+		return v.m.formatBig(v.V.(*big.Int))
+
+	case rational:
+		return v.m.formatRat(v.V.(*big.Rat))
+
+	case bigfloat:
+		return v.m.formatBigFloat(v.V.(*big.Float))
+
+	case datetime:
+		return v.m.formatDate(v.V.(time.Time))
+
+	case quantity:
+		q := v.V.(measure)
+		return v.m.formatFloat(q.V) + " " + q.U
+
 	case symbol:
 		return v.V.(*Symbol).S
 
@@ -136,6 +93,159 @@ func (v Value) String() string { // nolint:gocyclo
 	return "<nil>"
 }
 
+// formatFloat returns a float as a string
+// in the machine's current display mode.
+func (m *Machine) formatFloat(f float64) string {
+	switch m.disp {
+	case free:
+		// we don't need any special formatting
+		return fmt.Sprint(f)
+
+	case fixed:
+		return fmt.Sprintf("%.*f", m.digits, f)
+
+	case scientific:
+		return fmt.Sprintf("%.*e", m.digits, f)
+
+	case engineering:
+		// we have to calculate an exponent that's a multiple
+		// of three, and then scale the number to fit, and
+		// then make our own
+		n := f < 0
+		d := m.digits
+		s := '+'
+
+		// we only use the log of a positive number
+		// so if the original value is negative,
+		// we'll change it here, and change back later
+
+		if n {
+			f = -f
+		}
+
+		e := int(math.Round(math.Log10(f)))
+
+		//fmt.Printf("before: f=%v, e=%v, n=%v, d=%v, s=%q\n", f, e, n, d, s)
+
+		// we need to find the correct multiple of 3
+		// which is weird when it's a fractional number
+
+		if f == 0.0 {
+			e = 0
+		} else if e >= 0 {
+			e = (e / 3) * 3
+		} else {
+			e = (-e + 3) / 3 * (-3)
+		}
+
+		// scale the number by the new exponent
+
+		f *= math.Pow10(-e)
+
+		// and now, fix the digits as needed because fix=2
+		// (0.00) with 10 becomes 10.0 with two significant
+		// digits after the mantissa
+
+		if f >= 1000 {
+			f /= 1000
+			e += 3
+		} else if f >= 100 && d > 1 {
+			d -= 2
+		} else if f >= 10 && d > 0 {
+			d--
+		}
+
+		// fix the sign of the exponent, since we're
+		// making it here, not using %e, etc.
+
+		if e < 0 {
+			s = '-'
+			e = -e
+		}
+
+		//fmt.Printf(" after: f=%v, e=%v, n=%v, d=%v, s=%q\n", f, e, n, d, s)
+
+		// fiddle the negative number back now
+
+		if n {
+			f = -f
+		}
+
+		// we use .*f so we can tell the format how many
+		// digits to use as the variable d
+
+		return fmt.Sprintf("%.*fe%c%02d", d, f, s, e)
+	}
+
+	return fmt.Sprint(f)
+}
+
+// float returns the value of a number as a float,
+// or false if the value isn't a number.
+func (v Value) float() (float64, bool) {
+	switch v.T {
+	case floater:
+		return v.V.(float64), true
+
+	case integer:
+		if v.m != nil {
+			return v.m.ints.float(v.V.(uint)), true
+		}
+
+		return float64(v.V.(uint)), true
+
+	case bigint:
+		f, _ := new(big.Float).SetInt(v.V.(*big.Int)).Float64()
+		return f, true
+
+	case rational:
+		f, _ := v.V.(*big.Rat).Float64()
+		return f, true
+
+	case bigfloat:
+		f, _ := v.V.(*big.Float).Float64()
+		return f, true
+	}
+
+	return 0, false
+}
+
+// whole returns the value of a number as an int, or false
+// if it isn't a whole number from 0 to max (so NaN, infinite,
+// and fractional values are rejected).
+func (v Value) whole(max int) (int, bool) {
+	f, ok := v.float()
+
+	if !ok || !(f >= 0 && f <= float64(max)) || f != math.Trunc(f) {
+		return 0, false
+	}
+
+	return int(f), true
+}
+
+// toFloat returns a copy of a number as a float,
+// or false if the value isn't a number.
+func (v Value) toFloat() (*Value, bool) {
+	f, ok := v.float()
+
+	if !ok {
+		return nil, false
+	}
+
+	v.T, v.V = floater, f
+	return &v, true
+}
+
+// wordPlaces limits the minimum number of digits
+// to what's needed for the whole word.
+func wordPlaces(w uint, group, min int) int {
+	if n := (int(w) + group - 1) / group; n < min {
+		return n
+	}
+
+	return min
+}
+
 // places is used to see how many digits we need to
 // print for integers (including some minimum number
 // which is determined by the base), given how many
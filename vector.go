@@ -0,0 +1,244 @@
+package oak
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+var (
+	// StartVector marks the stack so that all the values
+	// pushed after it can be collected into a vector.
+	StartVector ExprFunc = func(m *Machine) error {
+		m.marks = append(m.marks, len(m.stack))
+		return nil
+	}
+
+	// EndVector collects all the numbers pushed onto the
+	// stack since the matching mark into a new vector, or
+	// all the vectors into a new matrix (one per row).
+	EndVector ExprFunc = func(m *Machine) error {
+		l := len(m.marks)
+
+		if l == 0 {
+			return fmt.Errorf("unmatched ]")
+		}
+
+		n := m.marks[l-1]
+		m.marks = m.marks[:l-1]
+
+		if n > len(m.stack) {
+			return fmt.Errorf("vector: %w", errUnderflow)
+		}
+
+		if l := len(m.stack); l > n && m.stack[l-1].T == vector {
+			a, err := makeMatrix(m.stack[n:])
+
+			if err != nil {
+				return err
+			}
+
+			m.stack = m.stack[:n]
+			m.Push(m.makeMatrixVal(a))
+			return nil
+		}
+
+		v := make([]float64, 0, len(m.stack)-n)
+
+		for _, e := range m.stack[n:] {
+			f, ok := e.float()
+
+			if !ok {
+				return fmt.Errorf("vector: invalid element %#v", e.V)
+			}
+
+			v = append(v, f)
+		}
+
+		m.stack = m.stack[:n]
+		m.Push(m.makeVectorVal(v))
+		return nil
+	}
+
+	Dot ExprFunc = func(m *Machine) error {
+		if len(m.stack) < 2 {
+			return errUnderflow
+		}
+
+		x := m.PopX()
+		y := m.Pop()
+
+		if x.T != vector || y.T != vector {
+			return fmt.Errorf("dot: invalid operands y=%#v, x=%#v", y.V, x.V)
+		}
+
+		a, b := y.V.([]float64), x.V.([]float64)
+
+		if len(a) != len(b) {
+			return fmt.Errorf("dot: mismatched lengths %d, %d", len(a), len(b))
+		}
+
+		m.Push(m.makeFloatVal(dotOf(a, b)))
+		return nil
+	}
+)
+
+// Reduction returns an expression which reduces a vector on
+// top of the stack to a single number; if the top of stack
+// isn't a vector, the other expression (if any) runs instead.
+func Reduction(op string, f func([]float64) float64, other Expr) ExprFunc {
+	return func(m *Machine) error {
+		if t := m.Top(); t != nil && t.T == vector {
+			x := m.PopX()
+
+			m.Push(m.makeFloatVal(f(x.V.([]float64))))
+			return nil
+		}
+
+		if other != nil {
+			return other.Eval(m)
+		}
+
+		if len(m.stack) < 1 {
+			return errUnderflow
+		}
+
+		return fmt.Errorf("%s: invalid operand x=%#v", op, m.Top().V)
+	}
+}
+
+var (
+	VectorSum  = Reduction("sum", sumOf, StatsOpAdd)
+	VectorProd = Reduction("prod", prodOf, nil)
+	VectorNorm = Reduction("norm", func(v []float64) float64 { return math.Sqrt(dotOf(v, v)) }, nil)
+)
+
+func sumOf(v []float64) float64 {
+	var s float64
+
+	for _, f := range v {
+		s += f
+	}
+
+	return s
+}
+
+func prodOf(v []float64) float64 {
+	p := 1.0
+
+	for _, f := range v {
+		p *= f
+	}
+
+	return p
+}
+
+func dotOf(a, b []float64) float64 {
+	var s float64
+
+	for i := range a {
+		s += a[i] * b[i]
+	}
+
+	return s
+}
+
+func minOf(v []float64) float64 {
+	if len(v) == 0 {
+		return math.NaN()
+	}
+
+	r := v[0]
+
+	for _, f := range v[1:] {
+		r = math.Min(r, f)
+	}
+
+	return r
+}
+
+func maxOf(v []float64) float64 {
+	if len(v) == 0 {
+		return math.NaN()
+	}
+
+	r := v[0]
+
+	for _, f := range v[1:] {
+		r = math.Max(r, f)
+	}
+
+	return r
+}
+
+// binaryVector applies the function element by element when
+// both operands are vectors (of the same length), or else
+// broadcasts the number over each element of the vector.
+func (m *Machine) binaryVector(op string, f func(float64, float64) float64, y, x *Value) (Value, error) {
+	switch {
+	case y.T == vector && x.T == vector:
+		a, b := y.V.([]float64), x.V.([]float64)
+
+		if len(a) != len(b) {
+			return Value{}, fmt.Errorf("%s: mismatched lengths %d, %d", op, len(a), len(b))
+		}
+
+		r := make([]float64, len(a))
+
+		for i := range a {
+			r[i] = f(a[i], b[i])
+		}
+
+		return m.makeVectorVal(r), nil
+
+	case y.T == vector:
+		if b, ok := x.float(); ok {
+			a := y.V.([]float64)
+			r := make([]float64, len(a))
+
+			for i := range a {
+				r[i] = f(a[i], b)
+			}
+
+			return m.makeVectorVal(r), nil
+		}
+
+	case x.T == vector:
+		if a, ok := y.float(); ok {
+			b := x.V.([]float64)
+			r := make([]float64, len(b))
+
+			for i := range b {
+				r[i] = f(a, b[i])
+			}
+
+			return m.makeVectorVal(r), nil
+		}
+	}
+
+	return Value{}, fmt.Errorf("%s: mismatched operands y=%#v, x=%#v", op, y.V, x.V)
+}
+
+// unaryVector applies the function to each element.
+func (m *Machine) unaryVector(f func(float64) float64, x *Value) Value {
+	a := x.V.([]float64)
+	r := make([]float64, len(a))
+
+	for i := range a {
+		r[i] = f(a[i])
+	}
+
+	return m.makeVectorVal(r)
+}
+
+// formatVector shows a vector's elements in the
+// current display mode, e.g., [1.00 2.00 3.00].
+func (m *Machine) formatVector(v []float64) string {
+	s := make([]string, len(v))
+
+	for i, f := range v {
+		s[i] = m.formatFloat(f)
+	}
+
+	return "[" + strings.Join(s, " ") + "]"
+}
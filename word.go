@@ -1,12 +1,19 @@
 package oak
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 
 	"oak/token"
 )
 
+// maxDepth limits how deeply words may call
+// themselves (or each other) recursively.
+const maxDepth = 1000
+
+var errRecursion = errors.New("recursion too deep")
+
 // Word represents a stack-based function (macro).
 //
 // The word contains its tokens and runs a separate
@@ -42,14 +49,16 @@ func (w *Word) Eval(m *Machine) error {
 
 	x := m.Top()
 
-	for _, e := range w.E {
-		if e == nil {
-			return fmt.Errorf("found nil expression")
-		}
+	if m.depth >= maxDepth {
+		return errRecursion
+	}
+
+	m.depth++
+
+	defer func() { m.depth-- }()
 
-		if err := e.Eval(m); err != nil {
-			return err
-		}
+	if err := evalList(m, w.E); err != nil {
+		return err
 	}
 
 	m.x = x
@@ -57,8 +66,9 @@ func (w *Word) Eval(m *Machine) error {
 	return nil
 }
 
-// Compile in its initial version will not support logic
-// or iteration, just calling a sequence of existing words.
+// Compile builds the word's expression list from its tokens;
+// control structures (if/else/then) compile into expressions
+// holding their own nested lists.
 func (w *Word) Compile(m *Machine) error {
 	var err error
 
@@ -71,9 +81,14 @@ func (w *Word) Compile(m *Machine) error {
 		return fmt.Errorf("invalid definition")
 	}
 
+	w.N = w.T[1].Text
+
+	// the word may refer to itself, even though
+	// it's not installed until it's compiled
+
 	p := WordParser(m, w.T[2:l-1], &w.S)
+	p.self = w
 
-	w.N = w.T[1].Text
 	w.E, err = p.Compile()
 
 	if err != nil {
@@ -0,0 +1,266 @@
+package oak
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"math/bits"
+)
+
+type complement uint
+
+const (
+	unsigned complement = iota
+	onesComp
+	twosComp
+)
+
+// intState holds the settings for integer arithmetic (the
+// word size and how negative numbers are represented) along
+// with the carry and overflow flags set by the last operation.
+type intState struct {
+	size  uint
+	comp  complement
+	carry bool
+	over  bool
+}
+
+var (
+	// SetWordSize pops the number of bits (1-64) in an
+	// integer, and truncates the integers on the stack.
+	SetWordSize ExprFunc = func(m *Machine) error {
+		if len(m.stack) < 1 {
+			return errUnderflow
+		}
+
+		x := m.Pop()
+		n, ok := x.whole(64)
+
+		if !ok || n < 1 {
+			return fmt.Errorf("ws: invalid operand x=%#v", x.V)
+		}
+
+		m.ints.size = uint(n)
+
+		for _, v := range m.stack {
+			if v.T == integer {
+				v.V = v.V.(uint) & m.ints.mask()
+			}
+		}
+
+		return nil
+	}
+
+	// Carry pushes the carry flag.
+	Carry ExprFunc = func(m *Machine) error {
+		m.Push(m.makeFlagVal(m.ints.carry))
+		return nil
+	}
+
+	// Overflow pushes the overflow flag.
+	Overflow ExprFunc = func(m *Machine) error {
+		m.Push(m.makeFlagVal(m.ints.over))
+		return nil
+	}
+)
+
+// WordMode returns the complement mode and word size
+// for integers, if other than unsigned 64-bit.
+func (m *Machine) WordMode() string {
+	if m.ints.comp == unsigned && m.ints.bits() == 64 {
+		return ""
+	}
+
+	return fmt.Sprintf("%s/%d", m.ints.comp, m.ints.bits())
+}
+
+func (c complement) String() string {
+	switch c {
+	case onesComp:
+		return "1s"
+	case twosComp:
+		return "2s"
+	}
+
+	return "unsigned"
+}
+
+func (s *intState) bits() uint {
+	if s.size == 0 {
+		return 64
+	}
+
+	return s.size
+}
+
+func (s *intState) mask() uint {
+	return ^uint(0) >> (64 - s.bits())
+}
+
+func (s *intState) sign(u uint) bool {
+	return s.comp != unsigned && u>>(s.bits()-1)&1 == 1
+}
+
+// signed returns the (signed) value of an integer
+// in the current complement mode.
+func (s *intState) signed(u uint) int64 {
+	switch {
+	case !s.sign(u):
+		return int64(u)
+	case s.comp == onesComp:
+		return -int64(^u & s.mask())
+	}
+
+	return int64(u | ^s.mask())
+}
+
+func (s *intState) float(u uint) float64 {
+	if s.comp == unsigned {
+		return float64(u)
+	}
+
+	return float64(s.signed(u))
+}
+
+func (s *intState) value(u uint) *big.Int {
+	if s.comp == unsigned {
+		return new(big.Int).SetUint64(uint64(u))
+	}
+
+	return big.NewInt(s.signed(u))
+}
+
+// inRange reports whether a result fits in the word.
+func (s *intState) inRange(r *big.Int) bool {
+	n := s.bits()
+
+	if s.comp == unsigned {
+		return r.Sign() >= 0 && r.BitLen() <= int(n)
+	}
+
+	// 2s complement has one more negative number
+
+	if s.comp == twosComp && r.Sign() < 0 {
+		return new(big.Int).Add(r, big.NewInt(1)).BitLen() < int(n)
+	}
+
+	return r.BitLen() < int(n)
+}
+
+// pattern returns the bits representing a number
+// in the current word size and complement mode.
+func (s *intState) pattern(r *big.Int) uint {
+	w := new(big.Int).Lsh(big.NewInt(1), s.bits())
+
+	if s.comp == onesComp && r.Sign() < 0 {
+		a := new(big.Int).Abs(r)
+		return ^uint(a.Mod(a, w).Uint64()) & s.mask()
+	}
+
+	return uint(new(big.Int).Mod(r, w).Uint64())
+}
+
+// fromFloat truncates a float to an integer
+// in the current word size and complement mode.
+func (s *intState) fromFloat(f float64) uint {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0
+	}
+
+	if f < 0 {
+		r, _ := big.NewFloat(f).Int(nil)
+		return s.pattern(r)
+	}
+
+	return uint(f) & s.mask()
+}
+
+// negate changes the sign of an integer.
+func (s *intState) negate(u uint) uint {
+	if s.comp == onesComp {
+		return ^u & s.mask()
+	}
+
+	return -u & s.mask()
+}
+
+// binaryInt does integer arithmetic in the current word size
+// and complement mode, setting the carry and overflow flags;
+// false means the operation should be done with floats.
+func (m *Machine) binaryInt(op string, y, x uint) (Value, bool, error) {
+	s := &m.ints
+	a, b := s.value(y), s.value(x)
+	r := new(big.Int)
+
+	switch op {
+	case "add":
+		// the carry is out of the top bit of the word
+
+		sum, c := bits.Add(y, x, 0)
+
+		if n := s.bits(); n < 64 {
+			s.carry = sum>>n != 0
+		} else {
+			s.carry = c == 1
+		}
+
+		r.Add(a, b)
+
+	case "sub":
+		// the carry is a borrow
+
+		r.Sub(a, b)
+		s.carry = y < x
+
+	case "mul":
+		r.Mul(a, b)
+		s.carry = false
+
+	case "div", "mod":
+		if b.Sign() == 0 {
+			return Value{}, true, fmt.Errorf("%s: division by zero", op)
+		}
+
+		q, rem := new(big.Int).QuoRem(a, b, new(big.Int))
+
+		if op == "div" {
+			r = q
+			s.carry = rem.Sign() != 0
+		} else {
+			r = rem
+			s.carry = false
+		}
+
+	default:
+		return Value{}, false, nil
+	}
+
+	s.over = !s.inRange(r)
+	return m.makeIntVal(s.pattern(r)), true, nil
+}
+
+// compareInt compares integers as they're interpreted
+// in the current complement mode.
+func (s *intState) compare(y, x uint) int {
+	if s.comp == unsigned {
+		switch {
+		case y < x:
+			return -1
+		case y > x:
+			return 1
+		}
+
+		return 0
+	}
+
+	a, b := s.signed(y), s.signed(x)
+
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+
+	return 0
+}